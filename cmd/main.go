@@ -2,12 +2,19 @@ package main
 
 import (
 	"fmt"
+	"log"
+	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ppondeu/go-post-api/internal/handler"
+	"github.com/ppondeu/go-post-api/internal/jobs"
+	"github.com/ppondeu/go-post-api/internal/mailer"
+	"github.com/ppondeu/go-post-api/internal/middleware"
 	"github.com/ppondeu/go-post-api/internal/repository"
 	"github.com/ppondeu/go-post-api/internal/routes"
 	"github.com/ppondeu/go-post-api/internal/usecase"
+	"github.com/ppondeu/go-post-api/internal/utils"
 	"github.com/ppondeu/go-post-api/internal/validate"
 
 	"github.com/ppondeu/go-post-api/config"
@@ -17,35 +24,155 @@ import (
 func main() {
 	cfg := config.LoadConfig()
 	db := database.ConnectDatabase(cfg)
+	if err := database.Migrate(db); err != nil {
+		log.Fatalf("Unable to migrate database: %v", err)
+	}
 	validate := validate.NewValidator()
 
+	transport := middleware.TokenTransport(cfg.TOKEN_TRANSPORT)
+	if !transport.Valid() {
+		log.Fatalf("Invalid TOKEN_TRANSPORT: %q", cfg.TOKEN_TRANSPORT)
+	}
+
+	cookieConfig := handler.CookieConfig{
+		Domain: cfg.COOKIE_DOMAIN,
+		Secure: cfg.COOKIE_SECURE,
+	}
+	switch strings.ToLower(cfg.COOKIE_SAME_SITE) {
+	case "lax":
+		cookieConfig.SameSite = http.SameSiteLaxMode
+	case "strict":
+		cookieConfig.SameSite = http.SameSiteStrictMode
+	case "none":
+		cookieConfig.SameSite = http.SameSiteNoneMode
+	default:
+		log.Fatalf("Invalid COOKIE_SAME_SITE: %q", cfg.COOKIE_SAME_SITE)
+	}
+	if cookieConfig.SameSite == http.SameSiteNoneMode && !cookieConfig.Secure {
+		log.Fatalf("COOKIE_SAME_SITE=none requires COOKIE_SECURE=true")
+	}
+
+	jwtService := usecase.NewJwtService([]byte(cfg.ACCESS_SECRET), []byte(cfg.REFRESH_SECRET))
+	if cfg.JWT_KEYS_DIR != "" {
+		signingKeys, err := usecase.LoadSigningKeys(cfg.JWT_KEYS_DIR)
+		if err != nil {
+			log.Fatalf("Unable to load signing keys: %v", err)
+		}
+		jwtService, err = usecase.NewAsymmetricJwtService(signingKeys, cfg.JWT_ACTIVE_KEY_ID, []byte(cfg.REFRESH_SECRET))
+		if err != nil {
+			log.Fatalf("Unable to create jwt service: %v", err)
+		}
+	}
+
+	var mail mailer.Mailer
+	if cfg.SMTP_HOST != "" {
+		mail = mailer.NewSMTPMailer(cfg.SMTP_HOST, cfg.SMTP_PORT, cfg.SMTP_USERNAME, cfg.SMTP_PASSWORD, cfg.MAIL_FROM)
+	} else {
+		fileMailer, err := mailer.NewFileMailer(cfg.MAIL_DIR)
+		if err != nil {
+			log.Fatalf("Unable to create mailer: %v", err)
+		}
+		mail = fileMailer
+	}
+
 	userRepo := repository.NewUserRepositoryDB(db)
-	userService := usecase.NewUserService(userRepo)
+	userTokenRepo := repository.NewUserTokenRepositoryDB(db)
+	userConfig := usecase.UserConfig{
+		AppBaseURL:           cfg.APP_BASE_URL,
+		EmailVerificationTTL: cfg.EMAIL_VERIFICATION_TTL,
+		DeletionGracePeriod:  cfg.ACCOUNT_DELETION_GRACE_PERIOD,
+	}
+	passwordHasher := utils.NewArgon2idHasher(utils.Argon2idParams{
+		Memory:      cfg.ARGON2_MEMORY,
+		Iterations:  cfg.ARGON2_ITERATIONS,
+		Parallelism: cfg.ARGON2_PARALLELISM,
+		SaltLength:  utils.DefaultArgon2idParams.SaltLength,
+		KeyLength:   utils.DefaultArgon2idParams.KeyLength,
+	})
+	passwordPolicy, err := utils.NewPasswordPolicy(cfg.PASSWORD_MIN_LENGTH, cfg.PASSWORD_MAX_LENGTH, cfg.PASSWORD_BREACHED_FILE)
+	if err != nil {
+		log.Fatalf("Unable to load password policy: %v", err)
+	}
+	auditEventRepo := repository.NewAuditEventRepositoryDB(db)
+	auditService := usecase.NewAuditService(auditEventRepo)
+	auditHandler := handler.NewAuditHandler(auditService, validate)
+
+	userService := usecase.NewUserService(userRepo, userTokenRepo, auditService, mail, passwordHasher, passwordPolicy, userConfig)
 	userHandler := handler.NewUserHandler(userService, validate)
+	accountHandler := handler.NewAccountHandler(userService, validate)
+	jobs.Every("purge deleted users", cfg.ACCOUNT_PURGE_INTERVAL, userService.PurgeDeletedUsers)
 
 	followRepo := repository.NewFollowRepositoryDB(db)
 	followService := usecase.NewFollowService(followRepo, userService)
 	followHandler := handler.NewFollowHandler(followService, validate)
 
-	jwtService := usecase.NewJwtService([]byte(cfg.ACCESS_SECRET), []byte(cfg.REFRESH_SECRET))
-	authService := usecase.NewAuthService(userService, jwtService)
-	authHandler := handler.NewAuthHandler(authService, validate)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepositoryDB(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepositoryDB(db)
+	loginThrottleConfig := usecase.LoginThrottleConfig{
+		MaxAccountAttempts: cfg.LOGIN_MAX_ACCOUNT_ATTEMPTS,
+		MaxIPAttempts:      cfg.LOGIN_MAX_IP_ATTEMPTS,
+		Window:             cfg.LOGIN_ATTEMPT_WINDOW,
+		BaseLockout:        cfg.LOGIN_LOCKOUT_BASE,
+		MaxLockout:         cfg.LOGIN_LOCKOUT_MAX,
+	}
+	loginThrottleService := usecase.NewLoginThrottleService(loginAttemptRepo, loginThrottleConfig)
+	authConfig := usecase.AuthConfig{
+		AppBaseURL:       cfg.APP_BASE_URL,
+		PasswordResetTTL: cfg.PASSWORD_RESET_TTL,
+		ImpersonationTTL: cfg.IMPERSONATION_TTL,
+		RefreshTokenTTL:  cfg.REFRESH_TOKEN_TTL,
+		TOTPIssuer:       cfg.TOTP_ISSUER,
+	}
+	oidcProviderConfigs := make([]usecase.OIDCProviderConfig, 0, len(cfg.OIDCProviders))
+	for _, provider := range cfg.OIDCProviders {
+		oidcProviderConfigs = append(oidcProviderConfigs, usecase.OIDCProviderConfig(provider))
+	}
+	externalIdentityRepo := repository.NewExternalIdentityRepositoryDB(db)
+	oidcService := usecase.NewOIDCService(oidcProviderConfigs, externalIdentityRepo, userService)
+	authService := usecase.NewAuthService(userService, jwtService, auditService, userTokenRepo, recoveryCodeRepo, loginThrottleService, oidcService, mail, authConfig)
+	authHandler := handler.NewAuthHandler(authService, validate, transport, cookieConfig)
 
 	postRepo := repository.NewPostRepositoryDB(db)
-	postService := usecase.NewPostService(postRepo, userService)
+	postConfig := usecase.PostConfig{
+		RequireVerifiedEmail: cfg.REQUIRE_VERIFIED_EMAIL,
+		TrashRetention:       cfg.TRASH_RETENTION,
+	}
+	postRevisionRepo := repository.NewPostRevisionRepositoryDB(db)
+	postService := usecase.NewPostService(postRepo, postRevisionRepo, userService, postConfig)
 	postHandler := handler.NewPostHandler(postService, validate)
+	jobs.Every("publish scheduled posts", cfg.POST_PUBLISH_INTERVAL, postService.PublishScheduledPosts)
+	jobs.Every("purge trash", cfg.TRASH_PURGE_INTERVAL, postService.PurgeTrash)
+
+	searchRepo := repository.NewSearchRepositoryDB(db)
+	searchService := usecase.NewSearchService(searchRepo)
+	searchHandler := handler.NewSearchHandler(searchService, validate)
+
+	jwksHandler := handler.NewJwksHandler(jwtService)
+
+	tokenRepo := repository.NewPersonalAccessTokenRepositoryDB(db)
+	tokenService := usecase.NewPersonalAccessTokenService(tokenRepo, userService)
+	tokenHandler := handler.NewTokenHandler(tokenService, validate)
 
 	router := gin.Default()
+	if transport.AllowsCookie() {
+		router.Use(middleware.CSRFProtection(transport))
+	}
 	router.GET("/ping", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"message": "pong",
 		})
 	})
 
-	routes.SetupUserRouter(router, userHandler)
-	routes.SetupAuthRouter(router, authHandler, &jwtService)
-	routes.SetupFollowRouter(router, followHandler)
-	routes.SetupPostRouter(router, postHandler)
+	routes.SetupUserRouter(router, userHandler, &jwtService, tokenService, transport)
+	routes.SetupAuthRouter(router, authHandler, &jwtService, transport)
+	routes.SetupTokenRouter(router, tokenHandler, &jwtService, transport)
+	routes.SetupAuditRouter(router, auditHandler, &jwtService, transport)
+	routes.SetupAccountRouter(router, accountHandler, &jwtService, transport)
+	routes.SetupFollowRouter(router, followHandler, &jwtService, tokenService, transport)
+	routes.SetupPostRouter(router, postHandler, &jwtService, tokenService, transport)
+	routes.SetupTagRouter(router, postHandler)
+	routes.SetupSearchRouter(router, searchHandler)
+	routes.SetupJwksRouter(router, jwksHandler)
 	fmt.Printf("Server running on port %v", cfg.SERVER_PORT)
 	router.Run(":" + cfg.SERVER_PORT)
 }
@@ -1,8 +1,11 @@
 package config
 
 import (
-	"github.com/spf13/viper"
 	"log"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
 )
 
 type Config struct {
@@ -14,15 +17,170 @@ type Config struct {
 	SERVER_PORT    string `mapstructure:"SERVER_PORT"`
 	ACCESS_SECRET  string `mapstructure:"ACCESS_SECRET"`
 	REFRESH_SECRET string `mapstructure:"REFRESH_SECRET"`
+
+	JWT_KEYS_DIR      string `mapstructure:"JWT_KEYS_DIR"`
+	JWT_ACTIVE_KEY_ID string `mapstructure:"JWT_ACTIVE_KEY_ID"`
+
+	TOKEN_TRANSPORT  string `mapstructure:"TOKEN_TRANSPORT"`
+	COOKIE_DOMAIN    string `mapstructure:"COOKIE_DOMAIN"`
+	COOKIE_SECURE    bool   `mapstructure:"COOKIE_SECURE"`
+	COOKIE_SAME_SITE string `mapstructure:"COOKIE_SAME_SITE"`
+
+	APP_BASE_URL       string        `mapstructure:"APP_BASE_URL"`
+	PASSWORD_RESET_TTL time.Duration `mapstructure:"PASSWORD_RESET_TTL"`
+	IMPERSONATION_TTL  time.Duration `mapstructure:"IMPERSONATION_TTL"`
+	REFRESH_TOKEN_TTL  time.Duration `mapstructure:"REFRESH_TOKEN_TTL"`
+
+	EMAIL_VERIFICATION_TTL time.Duration `mapstructure:"EMAIL_VERIFICATION_TTL"`
+	REQUIRE_VERIFIED_EMAIL bool          `mapstructure:"REQUIRE_VERIFIED_EMAIL"`
+
+	POST_PUBLISH_INTERVAL time.Duration `mapstructure:"POST_PUBLISH_INTERVAL"`
+	TRASH_RETENTION       time.Duration `mapstructure:"TRASH_RETENTION"`
+	TRASH_PURGE_INTERVAL  time.Duration `mapstructure:"TRASH_PURGE_INTERVAL"`
+
+	ACCOUNT_DELETION_GRACE_PERIOD time.Duration `mapstructure:"ACCOUNT_DELETION_GRACE_PERIOD"`
+	ACCOUNT_PURGE_INTERVAL        time.Duration `mapstructure:"ACCOUNT_PURGE_INTERVAL"`
+
+	TOTP_ISSUER string `mapstructure:"TOTP_ISSUER"`
+
+	PASSWORD_MIN_LENGTH    int    `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PASSWORD_MAX_LENGTH    int    `mapstructure:"PASSWORD_MAX_LENGTH"`
+	PASSWORD_BREACHED_FILE string `mapstructure:"PASSWORD_BREACHED_FILE"`
+	ARGON2_MEMORY          uint32 `mapstructure:"ARGON2_MEMORY"`
+	ARGON2_ITERATIONS      uint32 `mapstructure:"ARGON2_ITERATIONS"`
+	ARGON2_PARALLELISM     uint8  `mapstructure:"ARGON2_PARALLELISM"`
+
+	LOGIN_MAX_ACCOUNT_ATTEMPTS int           `mapstructure:"LOGIN_MAX_ACCOUNT_ATTEMPTS"`
+	LOGIN_MAX_IP_ATTEMPTS      int           `mapstructure:"LOGIN_MAX_IP_ATTEMPTS"`
+	LOGIN_ATTEMPT_WINDOW       time.Duration `mapstructure:"LOGIN_ATTEMPT_WINDOW"`
+	LOGIN_LOCKOUT_BASE         time.Duration `mapstructure:"LOGIN_LOCKOUT_BASE"`
+	LOGIN_LOCKOUT_MAX          time.Duration `mapstructure:"LOGIN_LOCKOUT_MAX"`
+
+	SMTP_HOST     string `mapstructure:"SMTP_HOST"`
+	SMTP_PORT     string `mapstructure:"SMTP_PORT"`
+	SMTP_USERNAME string `mapstructure:"SMTP_USERNAME"`
+	SMTP_PASSWORD string `mapstructure:"SMTP_PASSWORD"`
+	MAIL_FROM     string `mapstructure:"MAIL_FROM"`
+	MAIL_DIR      string `mapstructure:"MAIL_DIR"`
+
+	OIDC_PROVIDERS string               `mapstructure:"OIDC_PROVIDERS"`
+	OIDCProviders  []OIDCProviderConfig `mapstructure:"-"`
+}
+
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// loadOIDCProviders reads OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET,
+// _REDIRECT_URL and _SCOPES for every name listed in OIDC_PROVIDERS.
+func loadOIDCProviders(names string) []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       viper.GetString(prefix + "ISSUER"),
+			ClientID:     viper.GetString(prefix + "CLIENT_ID"),
+			ClientSecret: viper.GetString(prefix + "CLIENT_SECRET"),
+			RedirectURL:  viper.GetString(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(viper.GetString(prefix + "SCOPES")),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			log.Fatalf("OIDC provider %q needs %sISSUER, %sCLIENT_ID and %sREDIRECT_URL", name, prefix, prefix, prefix)
+		}
+		providers = append(providers, provider)
+	}
+	return providers
 }
 
 func LoadConfig() (config Config) {
 	viper.SetConfigFile(".env")
+	viper.SetDefault("COOKIE_SECURE", true)
 	if err := viper.ReadInConfig(); err != nil {
 		log.Fatalf("Error reading config file: %v", err)
 	}
 	if err := viper.Unmarshal(&config); err != nil {
 		log.Fatalf("Unable to decode into struct: %v", err)
 	}
+	if config.TOKEN_TRANSPORT == "" {
+		config.TOKEN_TRANSPORT = "both"
+	}
+	if config.COOKIE_SAME_SITE == "" {
+		config.COOKIE_SAME_SITE = "lax"
+	}
+	if config.PASSWORD_RESET_TTL == 0 {
+		config.PASSWORD_RESET_TTL = 30 * time.Minute
+	}
+	if config.IMPERSONATION_TTL == 0 {
+		config.IMPERSONATION_TTL = 15 * time.Minute
+	}
+	if config.REFRESH_TOKEN_TTL == 0 {
+		config.REFRESH_TOKEN_TTL = 7 * 24 * time.Hour
+	}
+	if config.EMAIL_VERIFICATION_TTL == 0 {
+		config.EMAIL_VERIFICATION_TTL = 24 * time.Hour
+	}
+	if config.ACCOUNT_DELETION_GRACE_PERIOD == 0 {
+		config.ACCOUNT_DELETION_GRACE_PERIOD = 30 * 24 * time.Hour
+	}
+	if config.ACCOUNT_PURGE_INTERVAL == 0 {
+		config.ACCOUNT_PURGE_INTERVAL = time.Hour
+	}
+	if config.POST_PUBLISH_INTERVAL == 0 {
+		config.POST_PUBLISH_INTERVAL = time.Minute
+	}
+	if config.TRASH_RETENTION == 0 {
+		config.TRASH_RETENTION = 30 * 24 * time.Hour
+	}
+	if config.TRASH_PURGE_INTERVAL == 0 {
+		config.TRASH_PURGE_INTERVAL = time.Hour
+	}
+	if config.TOTP_ISSUER == "" {
+		config.TOTP_ISSUER = "go-post-api"
+	}
+	if config.PASSWORD_MIN_LENGTH == 0 {
+		config.PASSWORD_MIN_LENGTH = 8
+	}
+	if config.PASSWORD_MAX_LENGTH == 0 {
+		config.PASSWORD_MAX_LENGTH = 256
+	}
+	if config.ARGON2_MEMORY == 0 {
+		config.ARGON2_MEMORY = 64 * 1024
+	}
+	if config.ARGON2_ITERATIONS == 0 {
+		config.ARGON2_ITERATIONS = 3
+	}
+	if config.ARGON2_PARALLELISM == 0 {
+		config.ARGON2_PARALLELISM = 2
+	}
+	if config.LOGIN_MAX_ACCOUNT_ATTEMPTS == 0 {
+		config.LOGIN_MAX_ACCOUNT_ATTEMPTS = 5
+	}
+	if config.LOGIN_MAX_IP_ATTEMPTS == 0 {
+		config.LOGIN_MAX_IP_ATTEMPTS = 20
+	}
+	if config.LOGIN_ATTEMPT_WINDOW == 0 {
+		config.LOGIN_ATTEMPT_WINDOW = 15 * time.Minute
+	}
+	if config.LOGIN_LOCKOUT_BASE == 0 {
+		config.LOGIN_LOCKOUT_BASE = time.Minute
+	}
+	if config.LOGIN_LOCKOUT_MAX == 0 {
+		config.LOGIN_LOCKOUT_MAX = time.Hour
+	}
+	if config.MAIL_DIR == "" {
+		config.MAIL_DIR = "./mail"
+	}
+	config.OIDCProviders = loadOIDCProviders(config.OIDC_PROVIDERS)
 	return
 }
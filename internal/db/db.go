@@ -23,8 +23,9 @@ func (s sqlLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql st
 func ConnectDatabase(cfg config.Config) *gorm.DB {
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Bangkok", cfg.DB_HOST, cfg.DB_USER, cfg.DB_PASSWORD, cfg.DB_NAME, cfg.DB_PORT)
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: sqlLogger{logger.Default.LogMode(logger.Info)},
-		DryRun: false,
+		Logger:         sqlLogger{logger.Default.LogMode(logger.Info)},
+		DryRun:         false,
+		TranslateError: true,
 	})
 	if err != nil {
 		panic(fmt.Sprintf("Failed to connect to database: %v", err))
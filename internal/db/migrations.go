@@ -0,0 +1,237 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// models are migrated together so that GORM can create the tables in the
+// order their foreign keys need.
+var models = []interface{}{
+	&domain.User{},
+	&domain.UserSession{},
+	&domain.UserToken{},
+	&domain.RecoveryCode{},
+	&domain.LoginAttempt{},
+	&domain.PersonalAccessToken{},
+	&domain.ExternalIdentity{},
+	&domain.OIDCLoginState{},
+	&domain.AuditEvent{},
+	&domain.Follow{},
+	&domain.Tag{},
+	&domain.Post{},
+	&domain.Comment{},
+	&domain.Like{},
+	&domain.Bookmark{},
+	&domain.PostRevision{},
+}
+
+// Migrate brings the schema up to date with the models. AutoMigrate only
+// adds tables, columns and indexes; the steps around it change what it
+// cannot. Every step checks whether it is still needed, so Migrate runs on
+// each start.
+func Migrate(db *gorm.DB) error {
+	// a user has a session per device now
+	if err := dropUniqueConstraints(db, "user_sessions", "user_id"); err != nil {
+		return err
+	}
+	if err := slugTags(db); err != nil {
+		return err
+	}
+	// a like or bookmark used to be unique per post rather than per user
+	if err := uniquePerUserAndPost(db, "likes", "idx_user_post_like"); err != nil {
+		return err
+	}
+	if err := uniquePerUserAndPost(db, "bookmarks", "idx_user_post_bookmark"); err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		return err
+	}
+	// sessions keep the ID of their refresh token, never the token itself
+	if err := dropColumn(db, "user_sessions", "refresh_token"); err != nil {
+		return err
+	}
+	if err := moveSecurityEvents(db); err != nil {
+		return err
+	}
+	if err := moveTagsToJoinTable(db); err != nil {
+		return err
+	}
+	// posts from before statuses existed went live when they were created
+	err := db.Model(&domain.Post{}).Unscoped().
+		Where("status = ? AND published_at IS NULL", domain.PostStatusPublished).
+		UpdateColumn("published_at", gorm.Expr("created_at")).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func dropColumn(db *gorm.DB, table, column string) error {
+	if !db.Migrator().HasColumn(table, column) {
+		return nil
+	}
+	return db.Migrator().DropColumn(table, column)
+}
+
+// dropUniqueConstraints drops the unique constraints on column alone, under
+// whatever name they were created. AutoMigrate only drops them under the
+// name current GORM versions give them.
+func dropUniqueConstraints(db *gorm.DB, table, column string) error {
+	if !db.Migrator().HasTable(table) {
+		return nil
+	}
+
+	var names []string
+	err := db.Raw(`SELECT con.conname FROM pg_constraint con
+		JOIN pg_attribute att ON att.attrelid = con.conrelid AND att.attnum = con.conkey[1]
+		WHERE con.conrelid = ?::regclass AND con.contype = 'u'
+			AND cardinality(con.conkey) = 1 AND att.attname = ?`, table, column).Scan(&names).Error
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := db.Migrator().DropConstraint(table, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uniquePerUserAndPost drops index when it does not cover user_id yet, so
+// AutoMigrate recreates it on user_id and post_id, and deletes all but one
+// row of each user and post, which the new index would reject.
+func uniquePerUserAndPost(db *gorm.DB, table, index string) error {
+	if !db.Migrator().HasTable(table) {
+		return nil
+	}
+
+	var definitions []string
+	err := db.Raw("SELECT indexdef FROM pg_indexes WHERE tablename = ? AND indexname = ?", table, index).Scan(&definitions).Error
+	if err != nil {
+		return err
+	}
+	if len(definitions) > 0 && strings.Contains(definitions[0], "user_id") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if len(definitions) > 0 {
+			if err := tx.Migrator().DropIndex(table, index); err != nil {
+				return err
+			}
+		}
+		return tx.Exec(`DELETE FROM ` + table + ` dup USING ` + table + ` kept
+			WHERE dup.user_id = kept.user_id AND dup.post_id = kept.post_id
+			AND dup.id > kept.id`).Error
+	})
+}
+
+// moveSecurityEvents copies the security events, which only ever recorded
+// refresh token reuse, into the audit log and drops their table.
+func moveSecurityEvents(db *gorm.DB) error {
+	if !db.Migrator().HasTable("security_events") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO audit_events (id, user_id, session_id, type, outcome, ip, user_agent, created_at)
+			SELECT id, user_id, session_id, type, ?, ip, user_agent, created_at FROM security_events
+			ON CONFLICT (id) DO NOTHING`, domain.AuditOutcomeFailure).Error
+		if err != nil {
+			return err
+		}
+		return tx.Migrator().DropTable("security_events")
+	})
+}
+
+// slugTags gives the tags from before slugs existed one, so the slug column
+// can be unique and not null. Nothing referenced tags back then, so of the
+// tags that share a slug only the first is kept.
+func slugTags(db *gorm.DB) error {
+	if !db.Migrator().HasTable("tags") || db.Migrator().HasColumn("tags", "slug") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("ALTER TABLE tags ADD COLUMN slug varchar(64)").Error; err != nil {
+			return err
+		}
+
+		var tags []struct {
+			ID   string
+			Name string
+		}
+		if err := tx.Table("tags").Select("id, name").Order("name").Find(&tags).Error; err != nil {
+			return err
+		}
+
+		// duplicates go first, so no renamed tag collides with one
+		kept := make(map[string]domain.Tag, len(tags))
+		for _, tag := range tags {
+			name, slug := utils.NormalizeTag(tag.Name)
+			if _, ok := kept[slug]; ok || slug == "" || len(slug) > domain.MaxTagSlugLength {
+				if err := tx.Exec("DELETE FROM tags WHERE id = ?", tag.ID).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			kept[slug] = domain.Tag{ID: tag.ID, Name: name, Slug: slug}
+		}
+		for _, tag := range kept {
+			if err := tx.Exec("UPDATE tags SET name = ?, slug = ? WHERE id = ?", tag.Name, tag.Slug, tag.ID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// moveTagsToJoinTable moves tags out of the old posts.tags array column,
+// which is dropped afterwards.
+func moveTagsToJoinTable(db *gorm.DB) error {
+	if !db.Migrator().HasColumn("posts", "tags") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var posts []struct {
+			ID   string
+			Tags pq.StringArray
+		}
+		if err := tx.Table("posts").Select("id, tags").Find(&posts).Error; err != nil {
+			return err
+		}
+
+		for _, post := range posts {
+			for _, tagName := range post.Tags {
+				name, slug := utils.NormalizeTag(tagName)
+				if slug == "" || len(slug) > domain.MaxTagSlugLength {
+					continue
+				}
+
+				tag := domain.Tag{Name: name, Slug: slug}
+				err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "slug"}}, DoNothing: true}).Create(&tag).Error
+				if err != nil {
+					return err
+				}
+				if err := tx.Where("slug = ?", slug).First(&tag).Error; err != nil {
+					return err
+				}
+
+				err = tx.Table("post_tags").Clauses(clause.OnConflict{DoNothing: true}).
+					Create(map[string]interface{}{"post_id": post.ID, "tag_id": tag.ID}).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Migrator().DropColumn("posts", "tags")
+	})
+}
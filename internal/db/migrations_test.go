@@ -0,0 +1,57 @@
+package db
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// tableModels lists the domain structs with a primary key, which are the
+// ones stored in a table of their own.
+func tableModels(t *testing.T) map[string]bool {
+	packages, err := parser.ParseDir(token.NewFileSet(), "../domain", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := make(map[string]bool)
+	for _, pkg := range packages {
+		ast.Inspect(pkg, func(node ast.Node) bool {
+			spec, ok := node.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			fields, ok := spec.Type.(*ast.StructType)
+			if !ok {
+				return false
+			}
+			for _, field := range fields.Fields.List {
+				if field.Tag != nil && strings.Contains(field.Tag.Value, "primaryKey") {
+					found[spec.Name.Name] = true
+				}
+			}
+			return false
+		})
+	}
+	return found
+}
+
+func TestEveryTableIsMigrated(t *testing.T) {
+	migrated := make(map[string]bool, len(models))
+	for _, model := range models {
+		migrated[reflect.TypeOf(model).Elem().Name()] = true
+	}
+
+	tables := tableModels(t)
+	if len(tables) == 0 {
+		t.Fatal("found no table models in the domain package")
+	}
+	for name := range tables {
+		if !migrated[name] {
+			t.Errorf("domain.%s has a table but is not in models", name)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditEventAccountCreated         = "account_created"
+	AuditEventAccountDeleted         = "account_deleted"
+	AuditEventDeletionRequested      = "account_deletion_requested"
+	AuditEventDeletionCancelled      = "account_deletion_cancelled"
+	AuditEventLogin                  = "login"
+	AuditEventLogout                 = "logout"
+	AuditEventTokenRefreshed         = "token_refreshed"
+	AuditEventRefreshTokenReuse      = "refresh_token_reuse"
+	AuditEventSessionRevoked         = "session_revoked"
+	AuditEventPasswordChanged        = "password_changed"
+	AuditEventPasswordResetRequested = "password_reset_requested"
+	AuditEventEmailChanged           = "email_changed"
+	AuditEventEmailVerified          = "email_verified"
+	AuditEventTwoFactorEnabled       = "two_factor_enabled"
+	AuditEventTwoFactorDisabled      = "two_factor_disabled"
+	AuditEventRoleChanged            = "role_changed"
+	AuditEventImpersonationStarted   = "impersonation_started"
+	AuditEventImpersonationStopped   = "impersonation_stopped"
+)
+
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditEvent rows are never updated or deleted and outlive the user they
+// refer to, so UserID has no foreign key.
+type AuditEvent struct {
+	ID        string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"ID"`
+	UserID    *string   `gorm:"type:uuid;index" json:"userID"`
+	ActorID   *string   `gorm:"type:uuid" json:"actorID"`
+	SessionID *string   `gorm:"type:uuid" json:"sessionID"`
+	Type      string    `gorm:"type:varchar(64);not null;index" json:"type"`
+	Outcome   string    `gorm:"type:varchar(16);not null" json:"outcome"`
+	IP        string    `gorm:"type:varchar(64);default:''" json:"ip"`
+	UserAgent string    `gorm:"type:varchar(512);default:''" json:"userAgent"`
+	Details   string    `gorm:"type:varchar(255);default:''" json:"details"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:current_timestamp;index" json:"createdAt"`
+}
+
+type AuditEventFilter struct {
+	UserID *uuid.UUID
+	Type   string
+	From   *time.Time
+	To     *time.Time
+}
+
+type AuditEventRepository interface {
+	Create(event *AuditEvent) error
+	Find(filter AuditEventFilter, page PageRequest) (*Page[AuditEvent], error)
+}
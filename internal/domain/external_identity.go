@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ExternalIdentity struct {
+	ID        string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"ID"`
+	UserID    string    `gorm:"type:uuid;not null;index" json:"userID"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Provider  string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_external_identity_provider_subject" json:"provider"`
+	Subject   string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_external_identity_provider_subject" json:"subject"`
+	Email     string    `gorm:"type:varchar(255);default:''" json:"email"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:current_timestamp" json:"createdAt"`
+}
+
+type OIDCLoginState struct {
+	ID           string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"ID"`
+	StateHash    string    `gorm:"type:char(64);not null;unique" json:"-"`
+	Provider     string    `gorm:"type:varchar(64);not null" json:"provider"`
+	CodeVerifier string    `gorm:"type:varchar(128);not null" json:"-"`
+	Nonce        string    `gorm:"type:varchar(128);not null" json:"-"`
+	ExpiresAt    time.Time `gorm:"type:timestamp;not null" json:"expiresAt"`
+	CreatedAt    time.Time `gorm:"type:timestamp;default:current_timestamp" json:"createdAt"`
+}
+
+type ExternalIdentityRepository interface {
+	Create(identity *ExternalIdentity) error
+	FindByProviderSubject(provider, subject string) (*ExternalIdentity, error)
+	FindByUserID(userID uuid.UUID) ([]ExternalIdentity, error)
+
+	CreateLoginState(state *OIDCLoginState) error
+	ConsumeLoginState(stateHash string) (*OIDCLoginState, error)
+	DeleteExpiredLoginStates() error
+}
@@ -19,6 +19,6 @@ type FollowRepository interface {
 	FindByID(ID uuid.UUID) (*Follow, error)
 	FindByFollowerIDAndFollowedID(followerID, followedID uuid.UUID) (*Follow, error)
 	Delete(ID uuid.UUID) error
-	FindFollowersByUserID(userID uuid.UUID) ([]Follow, error)
-	FindFollowedUsersByUserID(userID uuid.UUID) ([]Follow, error)
+	FindFollowersByUserID(userID uuid.UUID, page PageRequest) (*Page[Follow], error)
+	FindFollowedUsersByUserID(userID uuid.UUID, page PageRequest) (*Page[Follow], error)
 }
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+type LoginAttempt struct {
+	Key           string     `gorm:"type:varchar(320);primaryKey" json:"key"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastFailureAt time.Time  `gorm:"type:timestamp;not null" json:"lastFailureAt"`
+	LockedUntil   *time.Time `gorm:"type:timestamp" json:"lockedUntil"`
+}
+
+type LoginAttemptRepository interface {
+	FindByKey(key string) (*LoginAttempt, error)
+	Save(attempt *LoginAttempt) error
+	Delete(key string) error
+}
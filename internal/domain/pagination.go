@@ -0,0 +1,48 @@
+package domain
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// Cursor marks the last item of a page by its sort key and ID. Sort names
+// the ordering it belongs to, so a cursor cannot be reused with another one.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+type PageRequest struct {
+	Limit int
+	Sort  string
+	After *Cursor
+}
+
+type Page[T any] struct {
+	Items      []T
+	Limit      int
+	NextCursor *Cursor
+	HasMore    bool
+}
+
+const (
+	SortNewest    = "newest"
+	SortOldest    = "oldest"
+	SortPopular   = "popular"
+	SortUsername  = "username"
+	SortRelevance = "relevance"
+	SortRevision  = "revision"
+)
+
+// CursorTimeLayout formats timestamps in cursors the way Postgres stores
+// them, without a zone.
+const CursorTimeLayout = "2006-01-02T15:04:05.999999"
+
+func MapPage[T, U any](page *Page[T], f func(T) U) *Page[U] {
+	items := make([]U, 0, len(page.Items))
+	for _, item := range page.Items {
+		items = append(items, f(item))
+	}
+	return &Page[U]{Items: items, Limit: page.Limit, NextCursor: page.NextCursor, HasMore: page.HasMore}
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	ScopePostsWrite    = "posts:write"
+	ScopeCommentsWrite = "comments:write"
+	ScopeFollowsWrite  = "follows:write"
+	ScopeProfileWrite  = "profile:write"
+)
+
+type PersonalAccessToken struct {
+	ID         string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"ID"`
+	UserID     string         `gorm:"type:uuid;not null;index" json:"userID"`
+	User       User           `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Name       string         `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix     string         `gorm:"type:varchar(16);not null" json:"prefix"`
+	TokenHash  string         `gorm:"type:char(64);not null;unique" json:"-"`
+	Scopes     pq.StringArray `gorm:"type:text[];default:'{}'" json:"scopes"`
+	ExpiresAt  *time.Time     `gorm:"type:timestamp" json:"expiresAt"`
+	LastUsedAt *time.Time     `gorm:"type:timestamp" json:"lastUsedAt"`
+	CreatedAt  time.Time      `gorm:"type:timestamp;default:current_timestamp" json:"createdAt"`
+}
+
+type PersonalAccessTokenRepository interface {
+	Create(token *PersonalAccessToken) error
+	FindByID(ID uuid.UUID) (*PersonalAccessToken, error)
+	FindByHash(tokenHash string) (*PersonalAccessToken, error)
+	FindByUserID(userID uuid.UUID) ([]PersonalAccessToken, error)
+	UpdateLastUsed(ID uuid.UUID) error
+	Delete(ID uuid.UUID) error
+}
@@ -4,75 +4,137 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/lib/pq"
+	"gorm.io/gorm"
 )
 
+// DeletedCommentContent replaces the content of deleted comments that are
+// still shown because they have replies.
+const DeletedCommentContent = "[deleted]"
+
 type Comment struct {
-	ID        string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	Content   string    `gorm:"type:varchar(255);not null" json:"content"`
-	UserID    string    `gorm:"type:uuid;not null;" json:"userID"`
-	PostID    string    `gorm:"type:uuid;not null;" json:"postID"`
-	ParentID  *string   `gorm:"type:uuid;index" json:"parentID"`
-	Replies   []Comment `gorm:"foreignKey:ParentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"replies"`
-	CreatedAt time.Time `gorm:"type:timestamp;default:current_timestamp" json:"createdAt"`
-	UpdatedAt time.Time `gorm:"type:timestamp;default:current_timestamp;autoUpdateTime" json:"updatedAt"`
+	ID        string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Content   string         `gorm:"type:varchar(255);not null" json:"content"`
+	UserID    string         `gorm:"type:uuid;not null;" json:"userID"`
+	User      User           `gorm:"foreignKey:UserID" json:"-"`
+	PostID    string         `gorm:"type:uuid;not null;" json:"postID"`
+	ParentID  *string        `gorm:"type:uuid;index" json:"parentID"`
+	Replies   []Comment      `gorm:"foreignKey:ParentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"replies"`
+	CreatedAt time.Time      `gorm:"type:timestamp;default:current_timestamp" json:"createdAt"`
+	UpdatedAt time.Time      `gorm:"type:timestamp;default:current_timestamp;autoUpdateTime" json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"type:timestamp;index" json:"-"`
+	DeletedBy *string        `gorm:"type:uuid" json:"-"`
+
+	// maintained by Postgres, never read or written by GORM
+	SearchVector string `gorm:"type:tsvector GENERATED ALWAYS AS (setweight(to_tsvector('english', coalesce(content, '')), 'B')) STORED;index:idx_comments_search,type:gin;->:false;<-:false" json:"-"`
 }
 
 type Like struct {
 	ID        string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	UserID    string    `gorm:"type:uuid;not null" json:"userID"`
+	UserID    string    `gorm:"type:uuid;not null;uniqueIndex:idx_user_post_like" json:"userID"`
 	PostID    string    `gorm:"type:uuid;not null;uniqueIndex:idx_user_post_like" json:"postID"`
 	CreatedAt time.Time `gorm:"type:timestamp;default:current_timestamp" json:"createdAt"`
 }
 
 type Bookmark struct {
 	ID        string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	UserID    string    `gorm:"type:uuid;not null" json:"userID"`
+	UserID    string    `gorm:"type:uuid;not null;uniqueIndex:idx_user_post_bookmark" json:"userID"`
 	PostID    string    `gorm:"type:uuid;not null;uniqueIndex:idx_user_post_bookmark" json:"postID"`
 	Post      Post      `gorm:"foreignKey:PostID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"post"`
 	CreatedAt time.Time `gorm:"type:timestamp;default:current_timestamp" json:"createdAt"`
 }
 
+const (
+	MaxTagsPerPost   = 10
+	MaxTagSlugLength = 64
+)
+
 type Tag struct {
 	ID   string `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
 	Name string `gorm:"type:varchar(255);not null;unique" json:"name"`
+	Slug string `gorm:"type:varchar(64);not null;uniqueIndex" json:"slug"`
+	// only filled in by FindAllTags
+	PostCount int `gorm:"->;-:migration" json:"postCount"`
 }
 
+type PostStatus string
+
+// Only published posts are public. A scheduled post is published by the
+// scheduler once its PublishedAt has passed.
+const (
+	PostStatusDraft     PostStatus = "draft"
+	PostStatusScheduled PostStatus = "scheduled"
+	PostStatusPublished PostStatus = "published"
+	PostStatusArchived  PostStatus = "archived"
+)
+
 type Post struct {
-	ID        string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	Title     string         `gorm:"type:varchar(255);not null" json:"title"`
-	Content   string         `gorm:"not null" json:"content"`
-	ViewCount int            `gorm:"default:0" json:"viewCount"`
-	Tags      pq.StringArray `gorm:"type:text[];default:'{}'" json:"tags"`
-	UserID    string         `gorm:"type:uuid;not null" json:"userID"`
-	User      User           `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user"`
-	Likes     []Like         `gorm:"foreignKey:PostID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"likes,omitempty"`
-	Bookmarks []Bookmark     `gorm:"foreignKey:PostID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"bookmarks,omitempty"`
-	Comments  []Comment      `gorm:"foreignKey:PostID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"comments"`
+	ID          string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Title       string         `gorm:"type:varchar(255);not null" json:"title"`
+	Content     string         `gorm:"not null" json:"content"`
+	ViewCount   int            `gorm:"default:0" json:"viewCount"`
+	Tags        []Tag          `gorm:"many2many:post_tags;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"tags"`
+	UserID      string         `gorm:"type:uuid;not null" json:"userID"`
+	User        User           `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user"`
+	Likes       []Like         `gorm:"foreignKey:PostID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"likes,omitempty"`
+	Bookmarks   []Bookmark     `gorm:"foreignKey:PostID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"bookmarks,omitempty"`
+	Comments    []Comment      `gorm:"foreignKey:PostID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"comments"`
+	Status      PostStatus     `gorm:"type:varchar(16);not null;default:'published';index" json:"status"`
+	PublishedAt *time.Time     `gorm:"type:timestamp;index" json:"publishedAt"`
+	CreatedAt   time.Time      `gorm:"type:timestamp;default:current_timestamp;index" json:"createdAt"`
+	UpdatedAt   time.Time      `gorm:"type:timestamp;default:current_timestamp;autoUpdateTime" json:"updatedAt"`
+	DeletedAt   gorm.DeletedAt `gorm:"type:timestamp;index" json:"-"`
+	DeletedBy   *string        `gorm:"type:uuid" json:"-"`
+
+	// maintained by Postgres, never read or written by GORM
+	SearchVector string `gorm:"type:tsvector GENERATED ALWAYS AS (setweight(to_tsvector('english', coalesce(title, '')), 'A') || setweight(to_tsvector('english', coalesce(content, '')), 'B')) STORED;index:idx_posts_search,type:gin;->:false;<-:false" json:"-"`
+}
+
+type PostFilter struct {
+	AuthorID *uuid.UUID
+	Status   PostStatus
+	Tag      string
+	From     *time.Time
+	To       *time.Time
 }
 
 type PostRepository interface {
-	FindAll() ([]Post, error)
+	FindAll(filter PostFilter, page PageRequest) (*Page[Post], error)
 	FindByID(ID uuid.UUID) (*Post, error)
-	FindByUserID(userID uuid.UUID) ([]Post, error)
 	Save(post Post) (*Post, error)
+	// Update writes a set Status together with PublishedAt, so a nil
+	// PublishedAt clears it.
 	Update(ID uuid.UUID, post Post) (*Post, error)
-	Delete(ID uuid.UUID) error
+	// Delete moves the post to the trash of deletedBy.
+	Delete(ID, deletedBy uuid.UUID) error
+	FindDeleted(deletedBy uuid.UUID, page PageRequest) (*Page[Post], error)
+	FindDeletedByID(ID uuid.UUID) (*Post, error)
+	Restore(ID uuid.UUID) error
+	// PurgeDeleted removes posts and comments trashed before the given time
+	// for good.
+	PurgeDeleted(before time.Time) (int64, error)
+	SetStatus(ID uuid.UUID, status PostStatus, publishedAt *time.Time) error
+	// PublishDue publishes the scheduled posts whose time has come.
+	PublishDue(now time.Time) (int64, error)
 
 	FindAllTags() ([]Tag, error)
+	FindTagBySlug(slug string) (*Tag, error)
+	// FindOrCreateTags returns the stored tag for every slug, creating the
+	// missing ones.
+	FindOrCreateTags(tags []Tag) ([]Tag, error)
 	AddBookmark(bookmark Bookmark) error
 	RemoveBookmark(userID, postID uuid.UUID) error
 
-	CreateTag(tag Tag) (*Tag, error)
-
 	LikePost(like Like) error
 	UnlikePost(userID, postID uuid.UUID) error
 	GetPostLikeCount(postID uuid.UUID) (uint32, error)
 
 	AddComment(comment Comment) (*Comment, error)
 	UpdateComment(ID uuid.UUID, comment Comment) (*Comment, error)
-	DeleteComment(ID uuid.UUID) error
-	FindCommentsByPostID(postID uuid.UUID) ([]Comment, error)
+	DeleteComment(ID, deletedBy uuid.UUID) error
+	FindDeletedComments(deletedBy uuid.UUID, page PageRequest) (*Page[Comment], error)
+	FindDeletedCommentByID(ID uuid.UUID) (*Comment, error)
+	RestoreComment(ID uuid.UUID) error
+	FindCommentsByPostID(postID uuid.UUID, page PageRequest) (*Page[Comment], error)
 	FindCommentByID(ID uuid.UUID) (*Comment, error)
 	FindRepliesByCommentID(commentID uuid.UUID) ([]Comment, error)
 }
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PostRevision is a snapshot of a post taken every time it is created or
+// updated. Revisions are numbered from 1 per post and never change.
+type PostRevision struct {
+	ID        string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	PostID    string         `gorm:"type:uuid;not null;uniqueIndex:idx_post_revision_number" json:"postID"`
+	Post      Post           `gorm:"foreignKey:PostID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Number    int            `gorm:"not null;uniqueIndex:idx_post_revision_number" json:"number"`
+	AuthorID  string         `gorm:"type:uuid;not null" json:"authorID"`
+	Title     string         `gorm:"type:varchar(255);not null" json:"title"`
+	Content   string         `gorm:"not null" json:"content"`
+	Tags      pq.StringArray `gorm:"type:text[];default:'{}'" json:"tags"`
+	CreatedAt time.Time      `gorm:"type:timestamp;default:current_timestamp" json:"createdAt"`
+}
+
+type PostRevisionRepository interface {
+	FindByPostID(postID uuid.UUID, page PageRequest) (*Page[PostRevision], error)
+	FindByNumber(postID uuid.UUID, number int) (*PostRevision, error)
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RecoveryCode struct {
+	ID        string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"ID"`
+	UserID    string     `gorm:"type:uuid;not null;index" json:"userID"`
+	User      User       `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	CodeHash  string     `gorm:"type:char(64);not null" json:"-"`
+	UsedAt    *time.Time `gorm:"type:timestamp" json:"usedAt"`
+	CreatedAt time.Time  `gorm:"type:timestamp;default:current_timestamp" json:"createdAt"`
+}
+
+type RecoveryCodeRepository interface {
+	Replace(userID uuid.UUID, codeHashes []string) error
+	Use(userID uuid.UUID, codeHash string) (bool, error)
+	DeleteByUserID(userID uuid.UUID) error
+}
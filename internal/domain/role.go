@@ -0,0 +1,50 @@
+package domain
+
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+type Permission string
+
+const (
+	PermissionDeleteAnyPost    Permission = "posts:delete_any"
+	PermissionDeleteAnyComment Permission = "comments:delete_any"
+	PermissionDeleteAnyUser    Permission = "users:delete_any"
+	PermissionManageRoles      Permission = "users:manage_roles"
+	PermissionViewAuditLog     Permission = "audit:read"
+	PermissionImpersonate      Permission = "users:impersonate"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleUser: {},
+	RoleModerator: {
+		PermissionDeleteAnyPost,
+		PermissionDeleteAnyComment,
+	},
+	RoleAdmin: {
+		PermissionDeleteAnyPost,
+		PermissionDeleteAnyComment,
+		PermissionDeleteAnyUser,
+		PermissionManageRoles,
+		PermissionViewAuditLog,
+		PermissionImpersonate,
+	},
+}
+
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+func (r Role) HasPermission(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "github.com/google/uuid"
+
+const (
+	SearchTypePost    = "post"
+	SearchTypeComment = "comment"
+	SearchTypeUser    = "user"
+)
+
+// SearchQuery is a parsed search. Terms are ANDed; a phrase must match in
+// order and a prefix term matches any word starting with it.
+type SearchQuery struct {
+	Terms []SearchTerm
+	// empty means every type
+	Types    []string
+	AuthorID *uuid.UUID
+	Tag      string
+}
+
+type SearchTerm struct {
+	Text   string
+	Phrase bool
+	Prefix bool
+}
+
+// SearchResult is one ranked hit. Title is the post title or the username,
+// PostID is set for posts and comments, and Snippet is the HTML-escaped
+// matched text with hits wrapped in <mark>.
+type SearchResult struct {
+	Type     string
+	ID       string
+	Title    string
+	Snippet  string
+	Rank     float32
+	PostID   *string
+	AuthorID string
+}
+
+type SearchRepository interface {
+	Search(query SearchQuery, page PageRequest) (*Page[SearchResult], error)
+}
@@ -7,40 +7,74 @@ import (
 )
 
 type User struct {
-	ID          string      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	Username    string      `gorm:"unique;not null" json:"username"`
-	Email       string      `gorm:"unique;not null" json:"email"`
-	Password    string      `gorm:"not null" json:"password"`
-	ShortBio    string      `gorm:"type:varchar(160);default:''" json:"shortBio"`
-	UserSession UserSession `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"userSession"`
-	Posts       []Post      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"posts"`
-	Follower    []Follow    `gorm:"foreignKey:FollowerID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"follower"`
-	Followed    []Follow    `gorm:"foreignKey:FollowedID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"followed"`
-	Likes       []Like      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"likes,omitempty"`
-	Bookmarks   []Bookmark  `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"bookmarks"`
-	Comments    []Comment   `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"comments,omitempty"`
+	ID                string        `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Username          string        `gorm:"unique;not null" json:"username"`
+	Email             string        `gorm:"unique;not null" json:"email"`
+	EmailVerified     bool          `gorm:"not null;default:false" json:"emailVerified"`
+	Password          string        `gorm:"not null" json:"-"`
+	ShortBio          string        `gorm:"type:varchar(160);default:''" json:"shortBio"`
+	Role              Role          `gorm:"type:varchar(16);not null;default:'user'" json:"role"`
+	TwoFactorEnabled  bool          `gorm:"not null;default:false" json:"twoFactorEnabled"`
+	TwoFactorSecret   *string       `gorm:"type:varchar(64)" json:"-"`
+	TwoFactorLastStep int64         `gorm:"not null;default:0" json:"-"`
+	DeletionDueAt     *time.Time    `gorm:"type:timestamp;index" json:"-"`
+	UserSessions      []UserSession `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"userSessions"`
+	Posts             []Post        `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"posts"`
+	Follower          []Follow      `gorm:"foreignKey:FollowerID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"follower"`
+	Followed          []Follow      `gorm:"foreignKey:FollowedID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"followed"`
+	Likes             []Like        `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"likes,omitempty"`
+	Bookmarks         []Bookmark    `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"bookmarks"`
+	Comments          []Comment     `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"comments,omitempty"`
+
+	// maintained by Postgres, never read or written by GORM
+	SearchVector string `gorm:"type:tsvector GENERATED ALWAYS AS (setweight(to_tsvector('english', coalesce(username, '')), 'A') || setweight(to_tsvector('english', coalesce(short_bio, '')), 'C')) STORED;index:idx_users_search,type:gin;->:false;<-:false" json:"-"`
 }
 
 type UserSession struct {
-	ID           string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	UserID       string    `gorm:"type:uuid;not null;unique" json:"userID"`
-	RefreshToken *string   `gorm:"type:text;unique" json:"refreshToken"`
-	UpdatedAt    time.Time `gorm:"type:timestamp;default:current_timestamp;autoUpdateTime" json:"updatedAt"`
+	ID             string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID         string    `gorm:"type:uuid;not null;index" json:"userID"`
+	RefreshTokenID *string   `gorm:"type:uuid;unique" json:"-"`
+	UserAgent      string    `gorm:"type:varchar(512);default:''" json:"userAgent"`
+	IP             string    `gorm:"type:varchar(64);default:''" json:"ip"`
+	CreatedAt      time.Time `gorm:"type:timestamp;default:current_timestamp" json:"createdAt"`
+	LastUsedAt     time.Time `gorm:"type:timestamp;default:current_timestamp" json:"lastUsedAt"`
+	UpdatedAt      time.Time `gorm:"type:timestamp;default:current_timestamp;autoUpdateTime" json:"updatedAt"`
 }
 
 type UserRepository interface {
 	Create(user *User) (*User, error)
-	FindAll() ([]User, error)
+	FindAll(page PageRequest) (*Page[User], error)
 	FindByID(ID uuid.UUID) (*User, error)
 	FindByUsername(username string) (*User, error)
 	FindByEmail(email string) (*User, error)
 	FindUserWithRelation(ID uuid.UUID) (*User, error)
 	FindAllUsersWithRelation() ([]User, error)
+	// Update sets the non-zero fields of user. Changing the email marks it
+	// unverified in the same transaction.
 	Update(ID uuid.UUID, user *User) (*User, error)
 	Delete(ID uuid.UUID) error
-	CreateUserAndSession(user *User, refreshToken *string) (*User, error)
-	UpdateSession(userID uuid.UUID, refreshToken *string) error
-	FindSession(userID uuid.UUID) (*UserSession, error)
+	SetEmailVerified(ID uuid.UUID, verified bool) error
+	SetRole(ID uuid.UUID, role Role) error
+	SetPassword(ID uuid.UUID, password string) error
+	ResetPassword(tokenID, ID uuid.UUID, password string) (bool, error)
+	SetDeletionDueAt(ID uuid.UUID, dueAt *time.Time) error
+	FindDueForDeletion(now time.Time) ([]User, error)
+	// DeleteIfDue deletes the user only if its deletion is still due at now,
+	// so a deletion cancelled since FindDueForDeletion is kept.
+	DeleteIfDue(ID uuid.UUID, now time.Time) (bool, error)
+	UpdateTwoFactor(ID uuid.UUID, secret *string, enabled bool) error
+	SetTwoFactorLastStep(ID uuid.UUID, step int64) error
+	CreateUserAndSession(user *User, session *UserSession) (*User, error)
+
+	CreateSession(session *UserSession) (*UserSession, error)
+	FindSessionByID(ID uuid.UUID) (*UserSession, error)
+	FindSessionsByUserID(userID uuid.UUID) ([]UserSession, error)
+	// UpdateSession rotates the session's refresh token ID from
+	// oldRefreshTokenID to refreshTokenID, or returns gorm.ErrRecordNotFound
+	// if the session no longer has oldRefreshTokenID.
+	UpdateSession(ID uuid.UUID, oldRefreshTokenID, refreshTokenID string) error
+	DeleteSession(ID uuid.UUID) error
+	DeleteSessionsByUserID(userID uuid.UUID, exceptID *uuid.UUID) error
 
-	FindUserBookmarks(userID uuid.UUID) ([]Bookmark, error)
+	FindUserBookmarks(userID uuid.UUID, page PageRequest) (*Page[Bookmark], error)
 }
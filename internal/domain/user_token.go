@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	UserTokenPasswordReset     = "password_reset"
+	UserTokenEmailVerification = "email_verification"
+)
+
+type UserToken struct {
+	ID        string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"ID"`
+	UserID    string     `gorm:"type:uuid;not null;index" json:"userID"`
+	User      User       `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Purpose   string     `gorm:"type:varchar(32);not null" json:"purpose"`
+	TokenHash string     `gorm:"type:char(64);not null;unique" json:"-"`
+	ExpiresAt time.Time  `gorm:"type:timestamp;not null" json:"expiresAt"`
+	UsedAt    *time.Time `gorm:"type:timestamp" json:"usedAt"`
+	CreatedAt time.Time  `gorm:"type:timestamp;default:current_timestamp" json:"createdAt"`
+}
+
+type UserTokenRepository interface {
+	Create(token *UserToken) error
+	FindByHash(purpose, tokenHash string) (*UserToken, error)
+	MarkUsed(ID uuid.UUID) (bool, error)
+	DeleteByUserID(userID uuid.UUID, purpose string) error
+}
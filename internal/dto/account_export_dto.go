@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+type AccountExportDto struct {
+	ExportedAt time.Time            `json:"exportedAt"`
+	Profile    UserResponseDto      `json:"profile"`
+	Posts      []ExportedPostDto    `json:"posts"`
+	Comments   []ExportedCommentDto `json:"comments"`
+	Bookmarks  []ExportedPostRefDto `json:"bookmarks"`
+	Likes      []ExportedPostRefDto `json:"likes"`
+	Followers  []ExportedFollowDto  `json:"followers"`
+	Following  []ExportedFollowDto  `json:"following"`
+}
+
+type ExportedPostDto struct {
+	ID          string     `json:"ID"`
+	Title       string     `json:"title"`
+	Content     string     `json:"content"`
+	Tags        []string   `json:"tags"`
+	ViewCount   int        `json:"viewCount"`
+	Status      string     `json:"status"`
+	PublishedAt *time.Time `json:"publishedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+type ExportedCommentDto struct {
+	ID        string    `json:"ID"`
+	PostID    string    `json:"postID"`
+	ParentID  *string   `json:"parentID"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type ExportedPostRefDto struct {
+	PostID    string    `json:"postID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ExportedFollowDto struct {
+	UserID    string    `json:"userID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type AccountDeletionResponseDto struct {
+	DeletionDueAt time.Time `json:"deletionDueAt"`
+}
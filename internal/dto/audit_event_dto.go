@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+type AuditEventResponseDto struct {
+	ID        string    `json:"ID"`
+	UserID    *string   `json:"userID"`
+	ActorID   *string   `json:"actorID"`
+	SessionID *string   `json:"sessionID"`
+	Type      string    `json:"type"`
+	Outcome   string    `json:"outcome"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	Details   string    `json:"details"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type AuditEventQueryDto struct {
+	PageQueryDto
+	UserID string    `form:"userID" validate:"omitempty,uuid"`
+	Type   string    `form:"type" validate:"omitempty,max=64"`
+	From   time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
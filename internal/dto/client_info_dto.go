@@ -0,0 +1,6 @@
+package dto
+
+type ClientInfoDto struct {
+	UserAgent string
+	IP        string
+}
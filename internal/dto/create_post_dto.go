@@ -1,8 +1,12 @@
 package dto
 
+import "time"
+
 type CreatePostDto struct {
-	UserID  string   `json:"userID" validate:"required,uuid4"`
 	Title   string   `json:"title" validate:"required"`
 	Content string   `json:"content" validate:"required,min=3"`
 	Tags    []string `json:"tags" validate:"omitempty,dive,required,min=1"`
+	// defaults to published
+	Status    string     `json:"status" validate:"omitempty,oneof=draft scheduled published"`
+	PublishAt *time.Time `json:"publishAt" validate:"required_if=Status scheduled"`
 }
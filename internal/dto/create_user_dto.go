@@ -3,6 +3,6 @@ package dto
 type CreateUserDto struct {
 	Email    string `json:"email" validate:"required,email"`
 	Username string `json:"username" validate:"required,min=3,lowercase"`
-	Password string `json:"password" validate:"required,min=6"`
+	Password string `json:"password" validate:"required"`
 	ShortBio string `json:"shortBio" validate:"max=160"`
 }
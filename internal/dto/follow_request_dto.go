@@ -2,5 +2,4 @@ package dto
 
 type FollowRequestDto struct {
 	FollowedID string `json:"followedID" validate:"required,uuid"`
-	FollowerID string `json:"followerID" validate:"required,uuid"`
 }
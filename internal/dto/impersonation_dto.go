@@ -0,0 +1,6 @@
+package dto
+
+type StartImpersonationDto struct {
+	UserID string `json:"userID" validate:"required,uuid"`
+	Reason string `json:"reason" validate:"required,max=255"`
+}
@@ -1,6 +1,5 @@
 package dto
 
 type LikeDto struct {
-	UserID string `json:"userID"`
-	PostID string `json:"postID"`
+	PostID string `json:"postID" validate:"required,uuid"`
 }
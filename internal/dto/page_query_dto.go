@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type PageQueryDto struct {
+	Limit  int    `form:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor" validate:"omitempty,max=512"`
+}
+
+type PostQueryDto struct {
+	PageQueryDto
+	Sort   string    `form:"sort" validate:"omitempty,oneof=newest oldest popular"`
+	Author string    `form:"author" validate:"omitempty,uuid"`
+	Tag    string    `form:"tag" validate:"omitempty,max=255"`
+	From   time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type MyPostQueryDto struct {
+	PageQueryDto
+	Status string `form:"status" validate:"omitempty,oneof=draft scheduled published archived"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+type CreatePersonalAccessTokenDto struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,oneof=posts:write comments:write follows:write profile:write"`
+	ExpiresInDays *int     `json:"expiresInDays" validate:"omitempty,min=1,max=365"`
+}
+
+type PersonalAccessTokenResponseDto struct {
+	ID         string     `json:"ID"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+type PersonalAccessTokenCreatedDto struct {
+	PersonalAccessTokenResponseDto
+	Token string `json:"token"`
+}
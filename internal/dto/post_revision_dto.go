@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+type PostRevisionResponseDto struct {
+	Number    int       `json:"number"`
+	AuthorID  string    `json:"authorID"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type PostRevisionDiffQueryDto struct {
+	From int `form:"from" validate:"required,min=1"`
+	To   int `form:"to" validate:"required,min=1"`
+}
+
+type DiffLineDto struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// PostRevisionDiffDto describes the changes from revision From to revision
+// To. Title and Tags are only set when they differ.
+type PostRevisionDiffDto struct {
+	From    int             `json:"from"`
+	To      int             `json:"to"`
+	Title   *FieldChangeDto `json:"title,omitempty"`
+	Tags    *TagsChangeDto  `json:"tags,omitempty"`
+	Content []DiffLineDto   `json:"content"`
+}
+
+type FieldChangeDto struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type TagsChangeDto struct {
+	From []string `json:"from"`
+	To   []string `json:"to"`
+}
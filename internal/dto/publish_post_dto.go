@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+// PublishPostDto schedules the post when PublishAt is in the future and
+// publishes it right away otherwise.
+type PublishPostDto struct {
+	PublishAt *time.Time `json:"publishAt"`
+}
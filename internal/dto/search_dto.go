@@ -0,0 +1,19 @@
+package dto
+
+type SearchQueryDto struct {
+	PageQueryDto
+	Q      string `form:"q" validate:"required,max=256"`
+	Type   string `form:"type" validate:"omitempty,oneof=post comment user"`
+	Author string `form:"author" validate:"omitempty,uuid"`
+	Tag    string `form:"tag" validate:"omitempty,max=255"`
+}
+
+type SearchResultDto struct {
+	Type     string  `json:"type"`
+	ID       string  `json:"ID"`
+	Title    string  `json:"title"`
+	Snippet  string  `json:"snippet"`
+	Rank     float32 `json:"rank"`
+	PostID   *string `json:"postID,omitempty"`
+	AuthorID string  `json:"authorID"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+type SessionResponseDto struct {
+	ID         string    `json:"ID"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	Current    bool      `json:"current"`
+}
@@ -1,6 +1,12 @@
 package dto
 
+import "time"
+
 type TokenResponseDto struct {
-	AccessToken string `json:"accessToken"`
-	RefreshToken string `json:"refreshToken"`
+	AccessToken           string     `json:"accessToken,omitempty"`
+	AccessTokenExpiresAt  *time.Time `json:"accessTokenExpiresAt,omitempty"`
+	RefreshToken          string     `json:"refreshToken,omitempty"`
+	RefreshTokenExpiresAt *time.Time `json:"refreshTokenExpiresAt,omitempty"`
+	MfaRequired           bool       `json:"mfaRequired,omitempty"`
+	MfaToken              string     `json:"mfaToken,omitempty"`
 }
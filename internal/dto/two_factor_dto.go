@@ -0,0 +1,23 @@
+package dto
+
+type TwoFactorSetupResponseDto struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthURI"`
+}
+
+type TwoFactorCodeDto struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type TwoFactorLoginDto struct {
+	MfaToken string `json:"mfaToken" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type TwoFactorDisableDto struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type RecoveryCodesResponseDto struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
@@ -4,4 +4,6 @@ type UpdatePostDto struct {
 	Title   string   `json:"title" validate:"omitempty"`
 	Content string   `json:"content" validate:"omitempty,min=3"`
 	Tags    []string `json:"tags" validate:"omitempty,dive,required,min=1"`
+	// posts go live through the publish endpoint
+	Status string `json:"status" validate:"omitempty,oneof=draft archived"`
 }
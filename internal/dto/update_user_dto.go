@@ -1,7 +1,9 @@
 package dto
 
 type UpdateUserDto struct {
-	Username string `json:"username" validate:"omitempty,min=3,lowercase"`
-	Password string `json:"password" validate:"omitempty,min=6"`
-	ShortBio string `json:"shortBio" validate:"omitempty,max=160"`
+	Username        string `json:"username" validate:"omitempty,min=3,lowercase"`
+	Email           string `json:"email" validate:"omitempty,email"`
+	Password        string `json:"password" validate:"omitempty"`
+	ShortBio        string `json:"shortBio" validate:"omitempty,max=160"`
+	CurrentPassword string `json:"currentPassword" validate:"omitempty"`
 }
@@ -0,0 +1,5 @@
+package dto
+
+type UpdateUserRoleDto struct {
+	Role string `json:"role" validate:"required,oneof=user moderator admin"`
+}
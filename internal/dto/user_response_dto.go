@@ -1,32 +1,35 @@
 package dto
 
+import "time"
+
 type UserResponseDto struct {
 	ID       string `json:"ID"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	ShortBio string `json:"shortBio"`
+	Role     string `json:"role"`
 }
 
-type UserResponse struct {
-	ID       string       `json:"ID"`
-	Username string       `json:"username"`
-	Email    string       `json:"email"`
-	ShortBio string       `json:"shortBio"`
-	Session  SessionBrief `json:"session"`
-}
-
-type SessionBrief struct {
-	RefreshToken string `json:"refreshToken"`
+type UserProfileResponse struct {
+	UserResponseDto
+	Posts          []PostResponse `json:"posts"`
+	FollowerCount  int            `json:"followerCount"`
+	FollowingCount int            `json:"followingCount"`
 }
 
 type PostResponse struct {
-	ID        string `json:"ID"`
-	Title     string `json:"title"`
-	Content   string `json:"content"`
-	CreatedAt string `json:"createdAt"`
-	UpdatedAt string `json:"updatedAt"`
-	Author    Author `json:"author"`
-	Views     int    `json:"views"`
+	ID           string     `json:"ID"`
+	Title        string     `json:"title"`
+	Content      string     `json:"content"`
+	Tags         []string   `json:"tags"`
+	Author       Author     `json:"author"`
+	Views        int        `json:"views"`
+	LikeCount    int        `json:"likeCount"`
+	CommentCount int        `json:"commentCount"`
+	Status       string     `json:"status"`
+	PublishedAt  *time.Time `json:"publishedAt"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
 }
 
 type Author struct {
@@ -36,17 +39,34 @@ type Author struct {
 
 type CommentResponse struct {
 	ID        string          `json:"ID"`
+	PostID    string          `json:"postID"`
+	ParentID  *string         `json:"parentID"`
 	Content   string          `json:"content"`
-	CreatedAt string          `json:"createdAt"`
-	UpdatedAt string          `json:"updatedAt"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
 	Author    Author          `json:"author"`
+	Deleted   bool            `json:"deleted"`
 	Replies   []ReplyResponse `json:"replies"`
 }
 
 type ReplyResponse struct {
+	ID        string    `json:"ID"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Author    Author    `json:"author"`
+	Deleted   bool      `json:"deleted"`
+}
+
+type BookmarkResponse struct {
+	ID        string       `json:"ID"`
+	Post      PostResponse `json:"post"`
+	CreatedAt time.Time    `json:"createdAt"`
+}
+
+type TagResponse struct {
 	ID        string `json:"ID"`
-	Content   string `json:"content"`
-	CreatedAt string `json:"createdAt"`
-	UpdatedAt string `json:"updatedAt"`
-	Author    Author `json:"author"`
+	Name      string `json:"name"`
+	Slug      string `json:"slug"`
+	PostCount int    `json:"postCount"`
 }
@@ -0,0 +1,5 @@
+package dto
+
+type VerifyEmailDto struct {
+	Token string `json:"token" validate:"required"`
+}
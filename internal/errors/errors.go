@@ -39,6 +39,20 @@ func NewForbiddenError(message string) error {
 	}
 }
 
+func NewConflictError(message string) error {
+	return &AppError{
+		Code:    http.StatusConflict,
+		Message: message,
+	}
+}
+
+func NewTooManyRequestsError(message string) error {
+	return &AppError{
+		Code:    http.StatusTooManyRequests,
+		Message: message,
+	}
+}
+
 func NewBadRequestError(message string) error {
 	return &AppError{
 		Code:    http.StatusBadRequest,
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/ppondeu/go-post-api/internal/dto"
+	"github.com/ppondeu/go-post-api/internal/errors"
+	"github.com/ppondeu/go-post-api/internal/logger"
+	"github.com/ppondeu/go-post-api/internal/presenter"
+	"github.com/ppondeu/go-post-api/internal/response"
+	"github.com/ppondeu/go-post-api/internal/usecase"
+)
+
+type AccountHandler struct {
+	userService usecase.UserService
+	validator   *validator.Validate
+}
+
+func NewAccountHandler(userService usecase.UserService, validator *validator.Validate) *AccountHandler {
+	return &AccountHandler{
+		userService: userService,
+		validator:   validator,
+	}
+}
+
+func (h *AccountHandler) RequestDeletion(c *gin.Context) {
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	dueAt, err := h.userService.RequestDeletion(userID, getClientInfo(c))
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewSuccessResponse(c, dto.AccountDeletionResponseDto{DeletionDueAt: *dueAt})
+}
+
+// Export returns the archive as a download: a single JSON document by
+// default, or one JSON file per section with ?format=zip.
+func (h *AccountHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		response.NewErrorResponse(c, errors.NewBadRequestError("format must be json or zip"))
+		return
+	}
+
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	user, err := h.userService.GetUserWithRelation(userID)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	export := presenter.AccountExport(*user)
+
+	if format == "json" {
+		c.Header("Content-Disposition", `attachment; filename="account-export.json"`)
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	archive, err := exportArchive(export)
+	if err != nil {
+		logger.Error(err)
+		response.NewErrorResponse(c, errors.NewInternalServerError())
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="account-export.zip"`)
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+func exportArchive(export dto.AccountExportDto) ([]byte, error) {
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"posts.json", export.Posts},
+		{"comments.json", export.Comments},
+		{"bookmarks.json", export.Bookmarks},
+		{"likes.json", export.Likes},
+		{"followers.json", export.Followers},
+		{"following.json", export.Following},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
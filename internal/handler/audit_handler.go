@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/dto"
+	"github.com/ppondeu/go-post-api/internal/presenter"
+	"github.com/ppondeu/go-post-api/internal/response"
+	"github.com/ppondeu/go-post-api/internal/usecase"
+)
+
+type AuditHandler struct {
+	auditService usecase.AuditService
+	validator    *validator.Validate
+}
+
+func NewAuditHandler(auditService usecase.AuditService, validator *validator.Validate) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		validator:    validator,
+	}
+}
+
+func (h *AuditHandler) GetMySecurityEvents(c *gin.Context) {
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	page, err := bindPageRequest(c, h.validator, domain.SortNewest)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	events, err := h.auditService.GetUserEvents(userID, page)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	newPageResponse(c, domain.MapPage(events, presenter.AuditEvent))
+}
+
+func (h *AuditHandler) QueryAuditEvents(c *gin.Context) {
+	var auditEventQueryDto dto.AuditEventQueryDto
+	if err := bindQuery(c, h.validator, &auditEventQueryDto); err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	page, err := pageRequest(auditEventQueryDto.PageQueryDto, domain.SortNewest)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	filter := domain.AuditEventFilter{
+		Type: auditEventQueryDto.Type,
+	}
+	if auditEventQueryDto.UserID != "" {
+		userID := uuid.MustParse(auditEventQueryDto.UserID)
+		filter.UserID = &userID
+	}
+	if !auditEventQueryDto.From.IsZero() {
+		filter.From = &auditEventQueryDto.From
+	}
+	if !auditEventQueryDto.To.IsZero() {
+		filter.To = &auditEventQueryDto.To
+	}
+
+	events, err := h.auditService.QueryEvents(filter, page)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	newPageResponse(c, domain.MapPage(events, presenter.AuditEvent))
+}
@@ -1,25 +1,146 @@
 package handler
 
 import (
+	"crypto/subtle"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/ppondeu/go-post-api/internal/dto"
 	"github.com/ppondeu/go-post-api/internal/errors"
+	"github.com/ppondeu/go-post-api/internal/logger"
 	"github.com/ppondeu/go-post-api/internal/middleware"
 	"github.com/ppondeu/go-post-api/internal/response"
 	"github.com/ppondeu/go-post-api/internal/usecase"
+	"github.com/ppondeu/go-post-api/internal/utils"
 )
 
+type CookieConfig struct {
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+}
+
 type AuthHandler struct {
-	authService usecase.AuthService
-	validator   *validator.Validate
+	authService  usecase.AuthService
+	validator    *validator.Validate
+	transport    middleware.TokenTransport
+	cookieConfig CookieConfig
 }
 
-func NewAuthHandler(authService usecase.AuthService, validator *validator.Validate) *AuthHandler {
-	return &AuthHandler{authService: authService, validator: validator}
+func NewAuthHandler(authService usecase.AuthService, validator *validator.Validate, transport middleware.TokenTransport, cookieConfig CookieConfig) *AuthHandler {
+	return &AuthHandler{authService: authService, validator: validator, transport: transport, cookieConfig: cookieConfig}
+}
+
+func getPayloadUserID(c *gin.Context) (uuid.UUID, error) {
+	payload := c.MustGet("payload").(middleware.Payload)
+	userID, err := uuid.Parse(payload.Claims.Sub)
+	if err != nil {
+		return uuid.Nil, errors.NewBadRequestError("invalid user id")
+	}
+	return userID, nil
+}
+
+// getOptionalPayloadUserID returns uuid.Nil for anonymous requests.
+func getOptionalPayloadUserID(c *gin.Context) uuid.UUID {
+	if _, ok := c.Get("payload"); !ok {
+		return uuid.Nil
+	}
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		return uuid.Nil
+	}
+	return userID
+}
+
+func getPayloadSessionID(c *gin.Context) (uuid.UUID, error) {
+	payload := c.MustGet("payload").(middleware.Payload)
+	sessionID, err := uuid.Parse(payload.Claims.Sid)
+	if err != nil {
+		return uuid.Nil, errors.NewBadRequestError("invalid session id")
+	}
+	return sessionID, nil
+}
+
+func isPersonalAccessToken(c *gin.Context) bool {
+	payload := c.MustGet("payload").(middleware.Payload)
+	return payload.Claims.TokenType == "pat"
+}
+
+func getClientInfo(c *gin.Context) dto.ClientInfoDto {
+	return dto.ClientInfoDto{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+}
+
+func (h *AuthHandler) setCookie(c *gin.Context, name, value string, maxAge int, httpOnly bool) {
+	c.SetSameSite(h.cookieConfig.SameSite)
+	c.SetCookie(name, value, maxAge, "/", h.cookieConfig.Domain, h.cookieConfig.Secure, httpOnly)
+}
+
+func cookieMaxAge(expiresAt *time.Time) int {
+	if expiresAt == nil {
+		return 0
+	}
+	return int(time.Until(*expiresAt).Seconds())
+}
+
+// Clients that keep tokens themselves can send "X-Token-Transport: header"
+// to get the tokens in the response body only.
+func (h *AuthHandler) setAuthCookies(c *gin.Context, tokenResponseDto *dto.TokenResponseDto) {
+	if !h.transport.AllowsCookie() {
+		return
+	}
+	if h.transport.AllowsHeader() && c.GetHeader("X-Token-Transport") == string(middleware.TokenTransportHeader) {
+		return
+	}
+
+	csrfToken, err := utils.GenerateRandomToken()
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+
+	refreshMaxAge := cookieMaxAge(tokenResponseDto.RefreshTokenExpiresAt)
+	h.setCookie(c, "accessToken", tokenResponseDto.AccessToken, cookieMaxAge(tokenResponseDto.AccessTokenExpiresAt), true)
+	h.setCookie(c, "refreshToken", tokenResponseDto.RefreshToken, refreshMaxAge, true)
+	h.setCookie(c, middleware.CSRFCookieName, csrfToken, refreshMaxAge, false)
+}
+
+func (h *AuthHandler) clearAuthCookies(c *gin.Context) {
+	h.setCookie(c, "accessToken", "", -1, true)
+	h.setCookie(c, "refreshToken", "", -1, true)
+	h.setCookie(c, middleware.CSRFCookieName, "", -1, false)
+}
+
+func (h *AuthHandler) Register(c *gin.Context) {
+	var createUserDto dto.CreateUserDto
+	if err := c.ShouldBindJSON(&createUserDto); err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError("invalid json"))
+		return
+	}
+
+	if err := h.validator.Struct(createUserDto); err != nil {
+		if _, ok := err.(*validator.InvalidValidationError); ok {
+			response.NewErrorResponse(c, errors.NewBadRequestError("invalid json"))
+			return
+		}
+
+		response.NewErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	tokenResponseDto, err := h.authService.Register(createUserDto, getClientInfo(c))
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	h.setAuthCookies(c, tokenResponseDto)
+	response.NewCreatedResponse(c, tokenResponseDto)
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -39,20 +160,20 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	tokenResponseDto, err := h.authService.Login(authRequestDto)
+	tokenResponseDto, err := h.authService.Login(authRequestDto, getClientInfo(c))
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
-	c.SetCookie("accessToken", tokenResponseDto.AccessToken, 60*5, "/", "", false, true)
-	c.SetCookie("refreshToken", tokenResponseDto.RefreshToken, 60*9, "/", "", false, true)
+	if !tokenResponseDto.MfaRequired {
+		h.setAuthCookies(c, tokenResponseDto)
+	}
 	response.NewSuccessResponse(c, tokenResponseDto)
 }
 
 func (h *AuthHandler) Logout(c *gin.Context) {
 
 	payload := c.MustGet("payload").(middleware.Payload)
-	fmt.Printf("payload: %v\n", payload)
 
 	userId, err := uuid.Parse(payload.Claims.Sub)
 	if err != nil {
@@ -60,20 +181,18 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		return
 	}
 
-	err = h.authService.Logout(payload.Token, userId)
+	err = h.authService.Logout(payload.Token, userId, getClientInfo(c))
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
-	c.SetCookie("accessToken", "", -1, "/", "", false, true)
-	c.SetCookie("refreshToken", "", -1, "/", "", false, true)
+	h.clearAuthCookies(c)
 	response.NewSuccessResponse(c, nil)
 }
 
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 
 	payload := c.MustGet("payload").(middleware.Payload)
-	fmt.Printf("payload: %v\n", payload)
 
 	userId, err := uuid.Parse(payload.Claims.Sub)
 	if err != nil {
@@ -81,13 +200,341 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	tokenResponseDto, err := h.authService.RefreshToken(payload.Token, userId)
+	tokenResponseDto, err := h.authService.RefreshToken(payload.Token, userId, getClientInfo(c))
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
-	fmt.Println(tokenResponseDto)
-	c.SetCookie("accessToken", tokenResponseDto.AccessToken, 60*5, "/", "", false, true)
-	c.SetCookie("refreshToken", tokenResponseDto.RefreshToken, 60*9, "/", "", false, true)
+	h.setAuthCookies(c, tokenResponseDto)
 	response.NewSuccessResponse(c, tokenResponseDto)
 }
+
+const oidcStateCookieName = "oidcState"
+
+// setOIDCStateCookie ties a login to the browser that started it, so that a
+// callback URL from someone else's login can't sign the browser in to their
+// account. Strict cookies would not come back with the identity provider's
+// redirect.
+func (h *AuthHandler) setOIDCStateCookie(c *gin.Context, state string, maxAge int) {
+	sameSite := h.cookieConfig.SameSite
+	if sameSite == http.SameSiteStrictMode {
+		sameSite = http.SameSiteLaxMode
+	}
+	c.SetSameSite(sameSite)
+	c.SetCookie(oidcStateCookieName, state, maxAge, "/", h.cookieConfig.Domain, h.cookieConfig.Secure, true)
+}
+
+func (h *AuthHandler) OIDCLogin(c *gin.Context) {
+	authURL, state, err := h.authService.OIDCAuthorizationURL(c.Param("provider"))
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	h.setOIDCStateCookie(c, state, int(usecase.OIDCLoginStateTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	if providerError := c.Query("error"); providerError != "" {
+		response.NewErrorResponse(c, errors.NewUnauthorizedError(fmt.Sprintf("identity provider error: %s", providerError)))
+		return
+	}
+
+	code := c.Query("code")
+	state := c.Query("state")
+	if code == "" || state == "" {
+		response.NewErrorResponse(c, errors.NewBadRequestError("code and state are required"))
+		return
+	}
+
+	stateCookie, _ := c.Cookie(oidcStateCookieName)
+	h.setOIDCStateCookie(c, "", -1)
+	if stateCookie == "" || subtle.ConstantTimeCompare([]byte(stateCookie), []byte(state)) != 1 {
+		response.NewErrorResponse(c, errors.NewUnauthorizedError("login was not started by this browser"))
+		return
+	}
+
+	tokenResponseDto, err := h.authService.OIDCLogin(c.Param("provider"), code, state, getClientInfo(c))
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	if !tokenResponseDto.MfaRequired {
+		h.setAuthCookies(c, tokenResponseDto)
+	}
+	response.NewSuccessResponse(c, tokenResponseDto)
+}
+
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	sessionID, err := getPayloadSessionID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	sessions, err := h.authService.GetSessions(userID, sessionID)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewSuccessResponse(c, sessions)
+}
+
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError("id is invalid"))
+		return
+	}
+
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	err = h.authService.RevokeSession(userID, id, getClientInfo(c))
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewSuccessResponse(c, nil)
+}
+
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	sessionID, err := getPayloadSessionID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	err = h.authService.RevokeOtherSessions(userID, sessionID, getClientInfo(c))
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewSuccessResponse(c, nil)
+}
+
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var forgotPasswordDto dto.ForgotPasswordDto
+	if err := c.ShouldBindJSON(&forgotPasswordDto); err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError("invalid json"))
+		return
+	}
+
+	if err := h.validator.Struct(forgotPasswordDto); err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	err := h.authService.ForgotPassword(forgotPasswordDto.Email, getClientInfo(c))
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewSuccessResponse(c, nil)
+}
+
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var resetPasswordDto dto.ResetPasswordDto
+	if err := c.ShouldBindJSON(&resetPasswordDto); err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError("invalid json"))
+		return
+	}
+
+	if err := h.validator.Struct(resetPasswordDto); err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	err := h.authService.ResetPassword(resetPasswordDto.Token, resetPasswordDto.Password, getClientInfo(c))
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	h.clearAuthCookies(c)
+	response.NewSuccessResponse(c, nil)
+}
+
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var verifyEmailDto dto.VerifyEmailDto
+	if err := c.ShouldBindJSON(&verifyEmailDto); err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError("invalid json"))
+		return
+	}
+
+	if err := h.validator.Struct(verifyEmailDto); err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	err := h.authService.VerifyEmail(verifyEmailDto.Token, getClientInfo(c))
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewSuccessResponse(c, nil)
+}
+
+func (h *AuthHandler) ResendEmailVerification(c *gin.Context) {
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	err = h.authService.ResendEmailVerification(userID)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewSuccessResponse(c, nil)
+}
+
+func (h *AuthHandler) VerifyTwoFactorLogin(c *gin.Context) {
+	var twoFactorLoginDto dto.TwoFactorLoginDto
+	if err := c.ShouldBindJSON(&twoFactorLoginDto); err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError("invalid json"))
+		return
+	}
+
+	if err := h.validator.Struct(twoFactorLoginDto); err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	tokenResponseDto, err := h.authService.VerifyTwoFactorLogin(twoFactorLoginDto, getClientInfo(c))
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	h.setAuthCookies(c, tokenResponseDto)
+	response.NewSuccessResponse(c, tokenResponseDto)
+}
+
+func (h *AuthHandler) SetupTwoFactor(c *gin.Context) {
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	setup, err := h.authService.SetupTwoFactor(userID)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewSuccessResponse(c, setup)
+}
+
+func (h *AuthHandler) EnableTwoFactor(c *gin.Context) {
+	var twoFactorCodeDto dto.TwoFactorCodeDto
+	if err := c.ShouldBindJSON(&twoFactorCodeDto); err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError("invalid json"))
+		return
+	}
+
+	if err := h.validator.Struct(twoFactorCodeDto); err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	recoveryCodes, err := h.authService.EnableTwoFactor(userID, twoFactorCodeDto.Code, getClientInfo(c))
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewSuccessResponse(c, recoveryCodes)
+}
+
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	var twoFactorDisableDto dto.TwoFactorDisableDto
+	if err := c.ShouldBindJSON(&twoFactorDisableDto); err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError("invalid json"))
+		return
+	}
+
+	if err := h.validator.Struct(twoFactorDisableDto); err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	err = h.authService.DisableTwoFactor(userID, twoFactorDisableDto.Password, getClientInfo(c))
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewSuccessResponse(c, nil)
+}
+
+// Impersonation tokens are returned in the body and never replace the admin's
+// cookies, unless cookies are the only transport.
+func (h *AuthHandler) StartImpersonation(c *gin.Context) {
+	var startImpersonationDto dto.StartImpersonationDto
+	if err := c.ShouldBindJSON(&startImpersonationDto); err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError("invalid json"))
+		return
+	}
+
+	if err := h.validator.Struct(startImpersonationDto); err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	actorID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	userID := uuid.MustParse(startImpersonationDto.UserID)
+	tokenResponseDto, err := h.authService.StartImpersonation(actorID, userID, startImpersonationDto.Reason, getClientInfo(c))
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	if !h.transport.AllowsHeader() {
+		h.setCookie(c, "accessToken", tokenResponseDto.AccessToken, cookieMaxAge(tokenResponseDto.AccessTokenExpiresAt), true)
+	}
+	response.NewCreatedResponse(c, tokenResponseDto)
+}
+
+func (h *AuthHandler) StopImpersonation(c *gin.Context) {
+	payload := c.MustGet("payload").(middleware.Payload)
+
+	err := h.authService.StopImpersonation(payload.Claims, getClientInfo(c))
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	if !h.transport.AllowsHeader() {
+		h.setCookie(c, "accessToken", "", -1, true)
+	}
+	response.NewSuccessResponse(c, nil)
+}
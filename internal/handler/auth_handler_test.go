@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ppondeu/go-post-api/internal/dto"
+	"github.com/ppondeu/go-post-api/internal/middleware"
+	"github.com/ppondeu/go-post-api/internal/usecase"
+)
+
+// fakeAuthService embeds the interface so that reaching a method it does not
+// implement panics.
+type fakeAuthService struct {
+	usecase.AuthService
+	state string
+}
+
+func (s *fakeAuthService) OIDCAuthorizationURL(provider string) (string, string, error) {
+	return "https://idp.test/authorize?state=" + s.state, s.state, nil
+}
+
+func (s *fakeAuthService) OIDCLogin(provider, code, state string, clientInfo dto.ClientInfoDto) (*dto.TokenResponseDto, error) {
+	return &dto.TokenResponseDto{AccessToken: "access", RefreshToken: "refresh"}, nil
+}
+
+func TestOIDCCallbackNeedsTheStateCookieOfTheLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewAuthHandler(&fakeAuthService{state: "login-state"}, nil, middleware.TokenTransportHeader, CookieConfig{SameSite: http.SameSiteStrictMode})
+	router := gin.New()
+	router.GET("/oidc/:provider/login", h.OIDCLogin)
+	router.GET("/oidc/:provider/callback", h.OIDCCallback)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/oidc/idp/login", nil))
+	var stateCookie *http.Cookie
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == oidcStateCookieName {
+			stateCookie = cookie
+		}
+	}
+	if stateCookie == nil || !stateCookie.HttpOnly || stateCookie.SameSite == http.SameSiteStrictMode {
+		t.Fatalf("login set state cookie %v, want an HttpOnly, non-strict one", stateCookie)
+	}
+
+	tests := []struct {
+		name   string
+		cookie *http.Cookie
+		want   int
+	}{
+		{"no cookie", nil, http.StatusUnauthorized},
+		{"other login", &http.Cookie{Name: oidcStateCookieName, Value: "other-state"}, http.StatusUnauthorized},
+		{"same login", stateCookie, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/oidc/idp/callback?code=code&state=login-state", nil)
+		if tt.cookie != nil {
+			req.AddCookie(&http.Cookie{Name: tt.cookie.Name, Value: tt.cookie.Value})
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
 	"github.com/ppondeu/go-post-api/internal/dto"
 	"github.com/ppondeu/go-post-api/internal/errors"
 	"github.com/ppondeu/go-post-api/internal/logger"
@@ -31,10 +32,9 @@ func (h *FollowHandler) Follow(c *gin.Context) {
 		return
 	}
 
-	followerID, err := uuid.Parse(followeBody.FollowerID)
+	followerID, err := getPayloadUserID(c)
 	if err != nil {
-		logger.Error(err)
-		response.NewErrorResponse(c, errors.NewBadRequestError("follower_id is invalid"))
+		response.NewErrorResponse(c, err)
 		return
 	}
 
@@ -62,10 +62,9 @@ func (h *FollowHandler) Unfollow(c *gin.Context) {
 		return
 	}
 
-	followerID, err := uuid.Parse(followeBody.FollowerID)
+	followerID, err := getPayloadUserID(c)
 	if err != nil {
-		logger.Error(err)
-		response.NewErrorResponse(c, errors.NewBadRequestError("follower_id is invalid"))
+		response.NewErrorResponse(c, err)
 		return
 	}
 
@@ -93,13 +92,19 @@ func (h *FollowHandler) GetFollowers(c *gin.Context) {
 		return
 	}
 
-	followers, err := h.followService.GetFollowers(id)
+	page, err := bindPageRequest(c, h.validator, domain.SortNewest)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	followers, err := h.followService.GetFollowers(id, page)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
 
-	response.NewSuccessResponse(c, followers)
+	newPageResponse(c, followers)
 }
 
 func (h *FollowHandler) GetFollowedUsers(c *gin.Context) {
@@ -110,11 +115,17 @@ func (h *FollowHandler) GetFollowedUsers(c *gin.Context) {
 		return
 	}
 
-	followedUsers, err := h.followService.GetFollowedUsers(id)
+	page, err := bindPageRequest(c, h.validator, domain.SortNewest)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	followedUsers, err := h.followService.GetFollowedUsers(id, page)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
 
-	response.NewSuccessResponse(c, followedUsers)
+	newPageResponse(c, followedUsers)
 }
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ppondeu/go-post-api/internal/usecase"
+)
+
+type JwksHandler struct {
+	jwtService usecase.JwtService
+}
+
+func NewJwksHandler(jwtService usecase.JwtService) *JwksHandler {
+	return &JwksHandler{jwtService: jwtService}
+}
+
+func (h *JwksHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwtService.GetJWKS())
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/dto"
+	"github.com/ppondeu/go-post-api/internal/errors"
+	"github.com/ppondeu/go-post-api/internal/logger"
+	"github.com/ppondeu/go-post-api/internal/response"
+)
+
+func encodeCursor(cursor *domain.Cursor) string {
+	if cursor == nil {
+		return ""
+	}
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		logger.Error(err)
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (*domain.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor domain.Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(cursor.ID); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+var cursorRealPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// cursorValueValid checks a cursor value against the SQL type its sort
+// compares it as, so a tampered cursor is rejected before the cast fails in
+// the database.
+func cursorValueValid(sort, value string) bool {
+	if strings.ContainsRune(value, 0) {
+		return false
+	}
+
+	switch sort {
+	case domain.SortNewest, domain.SortOldest:
+		t, err := time.Parse(domain.CursorTimeLayout, value)
+		return err == nil && t.Year() >= 1
+	case domain.SortPopular, domain.SortRevision:
+		_, err := strconv.ParseInt(value, 10, 32)
+		return err == nil
+	case domain.SortRelevance:
+		if !cursorRealPattern.MatchString(value) {
+			return false
+		}
+		f, err := strconv.ParseFloat(value, 32)
+		return err == nil && !math.IsInf(f, 0)
+	case domain.SortUsername:
+		return true
+	}
+	return false
+}
+
+// bindQuery binds and validates the query string into query.
+func bindQuery(c *gin.Context, validator *validator.Validate, query interface{}) error {
+	if err := c.ShouldBindQuery(query); err != nil {
+		logger.Error(err)
+		return errors.NewBadRequestError("invalid query")
+	}
+
+	if err := validator.Struct(query); err != nil {
+		logger.Error(err)
+		return errors.NewBadRequestError(err.Error())
+	}
+	return nil
+}
+
+// pageRequest only accepts cursors that were issued for the same sort.
+func pageRequest(query dto.PageQueryDto, sort string) (domain.PageRequest, error) {
+	page := domain.PageRequest{Limit: query.Limit, Sort: sort}
+	if page.Limit == 0 {
+		page.Limit = domain.DefaultPageLimit
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor)
+		if err != nil || cursor.Sort != sort || !cursorValueValid(sort, cursor.Value) {
+			return page, errors.NewBadRequestError("cursor is invalid")
+		}
+		page.After = cursor
+	}
+	return page, nil
+}
+
+func bindPageRequest(c *gin.Context, validator *validator.Validate, sort string) (domain.PageRequest, error) {
+	var query dto.PageQueryDto
+	if err := bindQuery(c, validator, &query); err != nil {
+		return domain.PageRequest{}, err
+	}
+	return pageRequest(query, sort)
+}
+
+func newPageResponse[T any](c *gin.Context, page *domain.Page[T]) {
+	response.NewPaginatedResponse(c, page.Items, response.Pagination{
+		Limit:      page.Limit,
+		NextCursor: encodeCursor(page.NextCursor),
+		HasMore:    page.HasMore,
+	})
+}
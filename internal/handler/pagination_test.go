@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/dto"
+	"github.com/ppondeu/go-post-api/internal/errors"
+)
+
+func TestPageRequestValidatesCursorValue(t *testing.T) {
+	id := uuid.NewString()
+	tests := []struct {
+		sort  string
+		value string
+		valid bool
+	}{
+		{domain.SortNewest, "2024-05-01T10:20:30.123456", true},
+		{domain.SortNewest, "2024-05-01T10:20:30", true},
+		{domain.SortNewest, "yesterday", false},
+		{domain.SortNewest, "0000-01-01T00:00:00", false},
+		{domain.SortOldest, "2024-05-01 10:20:30", false},
+		{domain.SortPopular, "42", true},
+		{domain.SortPopular, "4.2", false},
+		{domain.SortPopular, "99999999999", false},
+		{domain.SortRevision, "7", true},
+		{domain.SortRevision, "1; DROP TABLE posts", false},
+		{domain.SortRelevance, "0.0607927", true},
+		{domain.SortRelevance, "1e-05", true},
+		{domain.SortRelevance, "NaN", false},
+		{domain.SortRelevance, "0x1p-2", false},
+		{domain.SortUsername, "alice", true},
+		{domain.SortUsername, "al\x00ice", false},
+	}
+	for _, tt := range tests {
+		cursor := encodeCursor(&domain.Cursor{Sort: tt.sort, Value: tt.value, ID: id})
+		page, err := pageRequest(dto.PageQueryDto{Cursor: cursor}, tt.sort)
+
+		if tt.valid {
+			if err != nil || page.After == nil || page.After.Value != tt.value {
+				t.Errorf("%s cursor %q: got %v, want it accepted", tt.sort, tt.value, err)
+			}
+			continue
+		}
+		appErr, ok := err.(*errors.AppError)
+		if !ok || appErr.Code != http.StatusBadRequest {
+			t.Errorf("%s cursor %q: got %v, want a bad request", tt.sort, tt.value, err)
+		}
+	}
+}
+
+func TestPageRequestRejectsCursorFromAnotherSort(t *testing.T) {
+	cursor := encodeCursor(&domain.Cursor{Sort: domain.SortPopular, Value: "3", ID: uuid.NewString()})
+
+	if _, err := pageRequest(dto.PageQueryDto{Cursor: cursor}, domain.SortNewest); err == nil {
+		t.Error("a popular cursor was accepted for newest")
+	}
+	if _, err := pageRequest(dto.PageQueryDto{Cursor: "not base64!"}, domain.SortNewest); err == nil {
+		t.Error("a garbage cursor was accepted")
+	}
+}
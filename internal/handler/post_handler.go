@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
 	"github.com/ppondeu/go-post-api/internal/dto"
 	"github.com/ppondeu/go-post-api/internal/errors"
 	"github.com/ppondeu/go-post-api/internal/logger"
+	"github.com/ppondeu/go-post-api/internal/presenter"
 	"github.com/ppondeu/go-post-api/internal/response"
 	"github.com/ppondeu/go-post-api/internal/usecase"
 )
@@ -24,12 +28,40 @@ func NewPostHandler(service usecase.PostService, validator *validator.Validate)
 }
 
 func (h *PostHandler) GetAllPosts(c *gin.Context) {
-	posts, err := h.postService.GetAllPosts()
+	var postQueryDto dto.PostQueryDto
+	if err := bindQuery(c, h.validator, &postQueryDto); err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	sort := postQueryDto.Sort
+	if sort == "" {
+		sort = domain.SortNewest
+	}
+	page, err := pageRequest(postQueryDto.PageQueryDto, sort)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	filter := domain.PostFilter{Tag: postQueryDto.Tag}
+	if postQueryDto.Author != "" {
+		authorID := uuid.MustParse(postQueryDto.Author)
+		filter.AuthorID = &authorID
+	}
+	if !postQueryDto.From.IsZero() {
+		filter.From = &postQueryDto.From
+	}
+	if !postQueryDto.To.IsZero() {
+		filter.To = &postQueryDto.To
+	}
+
+	posts, err := h.postService.GetAllPosts(filter, page)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
-	response.NewSuccessResponse(c, posts)
+	newPageResponse(c, domain.MapPage(posts, presenter.Post))
 }
 
 func (h *PostHandler) GetPostByID(c *gin.Context) {
@@ -40,13 +72,13 @@ func (h *PostHandler) GetPostByID(c *gin.Context) {
 		return
 	}
 
-	post, err := h.postService.GetPostByID(postId)
+	post, err := h.postService.GetPostForViewer(getOptionalPayloadUserID(c), postId)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
 
-	response.NewSuccessResponse(c, post)
+	response.NewSuccessResponse(c, presenter.Post(*post))
 }
 
 func (h *PostHandler) GetPostsByUserID(c *gin.Context) {
@@ -57,13 +89,19 @@ func (h *PostHandler) GetPostsByUserID(c *gin.Context) {
 		return
 	}
 
-	posts, err := h.postService.GetPostsByUserID(userId)
+	page, err := bindPageRequest(c, h.validator, domain.SortNewest)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
 
-	response.NewSuccessResponse(c, posts)
+	posts, err := h.postService.GetPostsByUserID(userId, page)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	newPageResponse(c, domain.MapPage(posts, presenter.Post))
 }
 
 func (h *PostHandler) CreatePost(c *gin.Context) {
@@ -84,13 +122,19 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 		return
 	}
 
-	post, err := h.postService.CreatePost(createPostDto)
+	userId, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	post, err := h.postService.CreatePost(userId, createPostDto)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
 
-	response.NewSuccessResponse(c, post)
+	response.NewSuccessResponse(c, presenter.Post(*post))
 }
 
 func (h *PostHandler) UpdatePost(c *gin.Context) {
@@ -119,13 +163,19 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 		return
 	}
 
-	post, err := h.postService.UpdatePost(postId, updatePostDto)
+	userId, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	post, err := h.postService.UpdatePost(userId, postId, updatePostDto)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
 
-	response.NewSuccessResponse(c, post)
+	response.NewSuccessResponse(c, presenter.Post(*post))
 }
 
 func (h *PostHandler) DeletePost(c *gin.Context) {
@@ -136,7 +186,13 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 		return
 	}
 
-	err = h.postService.DeletePost(postId)
+	userId, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	err = h.postService.DeletePost(userId, postId)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
@@ -150,7 +206,250 @@ func (h *PostHandler) GetTags(c *gin.Context) {
 		response.NewErrorResponse(c, err)
 		return
 	}
-	response.NewSuccessResponse(c, tags)
+	response.NewSuccessResponse(c, presenter.Tags(tags))
+}
+
+func (h *PostHandler) GetMyPosts(c *gin.Context) {
+	var myPostQueryDto dto.MyPostQueryDto
+	if err := bindQuery(c, h.validator, &myPostQueryDto); err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	page, err := pageRequest(myPostQueryDto.PageQueryDto, domain.SortNewest)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	posts, err := h.postService.GetMyPosts(userID, domain.PostStatus(myPostQueryDto.Status), page)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	newPageResponse(c, domain.MapPage(posts, presenter.Post))
+}
+
+func (h *PostHandler) PublishPost(c *gin.Context) {
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError("id is invalid"))
+		return
+	}
+
+	var publishPostDto dto.PublishPostDto
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&publishPostDto); err != nil {
+			logger.Error(err)
+			response.NewErrorResponse(c, errors.NewBadRequestError("invalid json"))
+			return
+		}
+	}
+
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	post, err := h.postService.PublishPost(userID, postID, publishPostDto.PublishAt)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewSuccessResponse(c, presenter.Post(*post))
+}
+
+func (h *PostHandler) GetRevisions(c *gin.Context) {
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError("id is invalid"))
+		return
+	}
+
+	page, err := bindPageRequest(c, h.validator, domain.SortRevision)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	revisions, err := h.postService.GetRevisions(userID, postID, page)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	newPageResponse(c, domain.MapPage(revisions, presenter.PostRevision))
+}
+
+func (h *PostHandler) DiffRevisions(c *gin.Context) {
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError("id is invalid"))
+		return
+	}
+
+	var diffQueryDto dto.PostRevisionDiffQueryDto
+	if err := bindQuery(c, h.validator, &diffQueryDto); err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	diff, err := h.postService.DiffRevisions(userID, postID, diffQueryDto.From, diffQueryDto.To)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewSuccessResponse(c, diff)
+}
+
+func (h *PostHandler) RestoreRevision(c *gin.Context) {
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError("id is invalid"))
+		return
+	}
+
+	number, err := strconv.Atoi(c.Param("rev"))
+	if err != nil || number < 1 {
+		response.NewErrorResponse(c, errors.NewBadRequestError("revision is invalid"))
+		return
+	}
+
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	post, err := h.postService.RestoreRevision(userID, postID, number)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewSuccessResponse(c, presenter.Post(*post))
+}
+
+func (h *PostHandler) GetTrashedPosts(c *gin.Context) {
+	page, err := bindPageRequest(c, h.validator, domain.SortNewest)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	posts, err := h.postService.GetDeletedPosts(userID, page)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	newPageResponse(c, domain.MapPage(posts, presenter.Post))
+}
+
+func (h *PostHandler) GetTrashedComments(c *gin.Context) {
+	page, err := bindPageRequest(c, h.validator, domain.SortNewest)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	comments, err := h.postService.GetDeletedComments(userID, page)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	newPageResponse(c, domain.MapPage(comments, presenter.TrashedComment))
+}
+
+func (h *PostHandler) RestorePost(c *gin.Context) {
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError("id is invalid"))
+		return
+	}
+
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	post, err := h.postService.RestorePost(userID, postID)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewSuccessResponse(c, presenter.Post(*post))
+}
+
+func (h *PostHandler) RestoreComment(c *gin.Context) {
+	commentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError("id is invalid"))
+		return
+	}
+
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	comment, err := h.postService.RestoreComment(userID, commentID)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewSuccessResponse(c, presenter.Comment(*comment))
+}
+
+func (h *PostHandler) GetPostsByTag(c *gin.Context) {
+	tag, err := h.postService.GetTagBySlug(c.Param("slug"))
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	page, err := bindPageRequest(c, h.validator, domain.SortNewest)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	posts, err := h.postService.GetAllPosts(domain.PostFilter{Tag: tag.Slug}, page)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	newPageResponse(c, domain.MapPage(posts, presenter.Post))
 }
 
 func (h *PostHandler) AddBookmark(c *gin.Context) {
@@ -171,7 +470,7 @@ func (h *PostHandler) AddBookmark(c *gin.Context) {
 		return
 	}
 
-	userId, err := uuid.Parse(createBookmarkDto.UserID)
+	userId, err := getPayloadUserID(c)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
@@ -210,7 +509,7 @@ func (h *PostHandler) RemoveBookmark(c *gin.Context) {
 		return
 	}
 
-	userId, err := uuid.Parse(removeBookmarkDto.UserID)
+	userId, err := getPayloadUserID(c)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
@@ -249,7 +548,7 @@ func (h *PostHandler) LikePost(c *gin.Context) {
 		return
 	}
 
-	userId, err := uuid.Parse(likeDto.UserID)
+	userId, err := getPayloadUserID(c)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
@@ -288,7 +587,7 @@ func (h *PostHandler) UnlikePost(c *gin.Context) {
 		return
 	}
 
-	userId, err := uuid.Parse(likeDto.UserID)
+	userId, err := getPayloadUserID(c)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
@@ -328,13 +627,19 @@ func (h *PostHandler) AddComment(c *gin.Context) {
 		return
 	}
 
-	comment, err := h.postService.AddComment(createCommentDto)
+	userID, err := getPayloadUserID(c)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
 
-	response.NewSuccessResponse(c, comment)
+	comment, err := h.postService.AddComment(userID, createCommentDto)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	response.NewSuccessResponse(c, presenter.Comment(*comment))
 }
 
 func (h *PostHandler) UpdateComment(c *gin.Context) {
@@ -363,13 +668,19 @@ func (h *PostHandler) UpdateComment(c *gin.Context) {
 		return
 	}
 
-	comment, err := h.postService.UpdateComment(commentID, updateCommentDto.Content)
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	comment, err := h.postService.UpdateComment(userID, commentID, updateCommentDto.Content)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
 
-	response.NewSuccessResponse(c, comment)
+	response.NewSuccessResponse(c, presenter.Comment(*comment))
 }
 
 func (h *PostHandler) DeleteComment(c *gin.Context) {
@@ -380,7 +691,13 @@ func (h *PostHandler) DeleteComment(c *gin.Context) {
 		return
 	}
 
-	err = h.postService.DeleteComment(commentID)
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	err = h.postService.DeleteComment(userID, commentID)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
@@ -396,13 +713,19 @@ func (h *PostHandler) GetCommentsByPostID(c *gin.Context) {
 		return
 	}
 
-	comments, err := h.postService.GetCommentsByPost(postID)
+	page, err := bindPageRequest(c, h.validator, domain.SortOldest)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	comments, err := h.postService.GetCommentsByPost(getOptionalPayloadUserID(c), postID, page)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
 
-	response.NewSuccessResponse(c, comments)
+	newPageResponse(c, domain.MapPage(comments, presenter.Comment))
 }
 
 func (h *PostHandler) GetCommentByID(c *gin.Context) {
@@ -413,11 +736,11 @@ func (h *PostHandler) GetCommentByID(c *gin.Context) {
 		return
 	}
 
-	comment, err := h.postService.GetCommentByID(commentID)
+	comment, err := h.postService.GetCommentByID(getOptionalPayloadUserID(c), commentID)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
 
-	response.NewSuccessResponse(c, comment)
+	response.NewSuccessResponse(c, presenter.Comment(*comment))
 }
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/dto"
+	"github.com/ppondeu/go-post-api/internal/presenter"
+	"github.com/ppondeu/go-post-api/internal/response"
+	"github.com/ppondeu/go-post-api/internal/usecase"
+)
+
+type SearchHandler struct {
+	searchService usecase.SearchService
+	validator     *validator.Validate
+}
+
+func NewSearchHandler(service usecase.SearchService, validator *validator.Validate) *SearchHandler {
+	return &SearchHandler{
+		searchService: service,
+		validator:     validator,
+	}
+}
+
+func (h *SearchHandler) Search(c *gin.Context) {
+	var searchQueryDto dto.SearchQueryDto
+	if err := bindQuery(c, h.validator, &searchQueryDto); err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	page, err := pageRequest(searchQueryDto.PageQueryDto, domain.SortRelevance)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	results, err := h.searchService.Search(searchQueryDto, page)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	newPageResponse(c, domain.MapPage(results, presenter.SearchResult))
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/dto"
+	"github.com/ppondeu/go-post-api/internal/errors"
+	"github.com/ppondeu/go-post-api/internal/logger"
+	"github.com/ppondeu/go-post-api/internal/response"
+	"github.com/ppondeu/go-post-api/internal/usecase"
+)
+
+type TokenHandler struct {
+	tokenService usecase.PersonalAccessTokenService
+	validator    *validator.Validate
+}
+
+func NewTokenHandler(tokenService usecase.PersonalAccessTokenService, validator *validator.Validate) *TokenHandler {
+	return &TokenHandler{
+		tokenService: tokenService,
+		validator:    validator,
+	}
+}
+
+func (h *TokenHandler) GetTokens(c *gin.Context) {
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	tokens, err := h.tokenService.GetTokens(userID)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewSuccessResponse(c, tokens)
+}
+
+func (h *TokenHandler) CreateToken(c *gin.Context) {
+	var createTokenDto dto.CreatePersonalAccessTokenDto
+	if err := c.ShouldBindJSON(&createTokenDto); err != nil {
+		logger.Error(err)
+		response.NewErrorResponse(c, errors.NewBadRequestError("invalid json"))
+		return
+	}
+
+	if err := h.validator.Struct(createTokenDto); err != nil {
+		logger.Error(err)
+		response.NewErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	token, err := h.tokenService.CreateToken(userID, createTokenDto)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewCreatedResponse(c, token)
+}
+
+func (h *TokenHandler) RevokeToken(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		response.NewErrorResponse(c, errors.NewBadRequestError("id is invalid"))
+		return
+	}
+
+	userID, err := getPayloadUserID(c)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	err = h.tokenService.RevokeToken(userID, id)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+	response.NewSuccessResponse(c, nil)
+}
@@ -1,14 +1,14 @@
 package handler
 
 import (
-	"fmt"
-
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
 	"github.com/ppondeu/go-post-api/internal/dto"
 	"github.com/ppondeu/go-post-api/internal/errors"
 	"github.com/ppondeu/go-post-api/internal/logger"
+	"github.com/ppondeu/go-post-api/internal/presenter"
 	"github.com/ppondeu/go-post-api/internal/response"
 	"github.com/ppondeu/go-post-api/internal/usecase"
 )
@@ -26,48 +26,30 @@ func NewUserHandler(service usecase.UserService, validator *validator.Validate)
 }
 
 func (h *UserHandler) GetAllUsers(c *gin.Context) {
-	users, err := h.userService.GetAllUsers()
+	page, err := bindPageRequest(c, h.validator, domain.SortUsername)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
-	usersResponse := make([]dto.UserResponseDto, 0)
-	for _, user := range users {
-		usersResponse = append(usersResponse, dto.UserResponseDto{
-			ID:       user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-			ShortBio: user.ShortBio,
-		})
-	}
-	response.NewSuccessResponse(c, usersResponse)
-}
-
-func (h *UserHandler) GetUserByID(c *gin.Context) {
-
-	idParam := c.Param("id")
-	id, err := uuid.Parse(idParam)
-	if err != nil {
-		response.NewErrorResponse(c, errors.NewBadRequestError("id is invalid"))
-		return
-	}
 
-	user, err := h.userService.GetUserByID(id)
+	users, err := h.userService.GetAllUsers(page)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
+	newPageResponse(c, domain.MapPage(users, presenter.User))
+}
 
-	userResponse := dto.UserResponseDto{
-		ID:       user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		ShortBio: user.ShortBio,
+// visibleUser hides accounts pending deletion, like GetAllUsers does.
+func visibleUser(user *domain.User, err error) (*domain.User, error) {
+	if err == nil && user.DeletionDueAt != nil {
+		return nil, errors.NewNotFoundError("User not found")
 	}
-	response.NewSuccessResponse(c, userResponse)
+	return user, err
 }
 
-func (h *UserHandler) GetUserSession(c *gin.Context) {
+func (h *UserHandler) GetUserByID(c *gin.Context) {
+
 	idParam := c.Param("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
@@ -75,44 +57,36 @@ func (h *UserHandler) GetUserSession(c *gin.Context) {
 		return
 	}
 
-	session, err := h.userService.GetUserSession(id)
+	user, err := visibleUser(h.userService.GetUserByID(id))
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
-	response.NewSuccessResponse(c, session)
+
+	userResponse := presenter.User(*user)
+	response.NewSuccessResponse(c, userResponse)
 }
 
 func (h *UserHandler) GetUserByUsername(c *gin.Context) {
 	username := c.Param("username")
-	user, err := h.userService.GetUserByUsername(username)
+	user, err := visibleUser(h.userService.GetUserByUsername(username))
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
-	userResponse := dto.UserResponseDto{
-		ID:       user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		ShortBio: user.ShortBio,
-	}
+	userResponse := presenter.User(*user)
 	response.NewSuccessResponse(c, userResponse)
 }
 
 func (h *UserHandler) GetUserByEmail(c *gin.Context) {
 	email := c.Param("email")
-	user, err := h.userService.GetUserByEmail(email)
+	user, err := visibleUser(h.userService.GetUserByEmail(email))
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
 
-	userResponse := dto.UserResponseDto{
-		ID:       user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		ShortBio: user.ShortBio,
-	}
+	userResponse := presenter.User(*user)
 
 	response.NewSuccessResponse(c, userResponse)
 }
@@ -136,25 +110,19 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.CreateUserAndSession(&createUserDto, nil)
+	user, err := h.userService.CreateUser(&createUserDto)
 	if err != nil {
 		logger.Error(err)
 		response.NewErrorResponse(c, err)
 		return
 	}
 
-	userResponse := dto.UserResponseDto{
-		ID:       user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		ShortBio: user.ShortBio,
-	}
+	userResponse := presenter.User(*user)
 	response.NewCreatedResponse(c, userResponse)
 }
 
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	idParam := c.Param("id")
-	logger.Info(fmt.Sprintf("idParam: %v", idParam))
 	id, err := uuid.Parse(idParam)
 	if err != nil {
 		response.NewErrorResponse(c, errors.NewBadRequestError("id is invalid"))
@@ -179,23 +147,40 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.UpdateUser(id, &updateUserDto)
+	// the email and password are account security, which personal access
+	// tokens never reach
+	if (updateUserDto.Email != "" || updateUserDto.Password != "") && isPersonalAccessToken(c) {
+		response.NewErrorResponse(c, errors.NewForbiddenError("Personal access tokens can't change the email or password"))
+		return
+	}
+
+	actorID, err := getPayloadUserID(c)
 	if err != nil {
-		logger.Error(err)
 		response.NewErrorResponse(c, err)
 		return
 	}
 
-	userResponse := dto.UserResponseDto{
-		ID:       user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		ShortBio: user.ShortBio,
+	var sessionID uuid.UUID
+	if updateUserDto.Password != "" {
+		sessionID, err = getPayloadSessionID(c)
+		if err != nil {
+			response.NewErrorResponse(c, err)
+			return
+		}
+	}
+
+	user, err := h.userService.UpdateUser(actorID, id, sessionID, &updateUserDto, getClientInfo(c))
+	if err != nil {
+		logger.Error(err)
+		response.NewErrorResponse(c, err)
+		return
 	}
+
+	userResponse := presenter.User(*user)
 	response.NewSuccessResponse(c, userResponse)
 }
 
-func (h *UserHandler) DeleteUser(c *gin.Context) {
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
@@ -203,48 +188,59 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	err = h.userService.DeleteUser(id)
+	var updateUserRoleDto dto.UpdateUserRoleDto
+	if err := c.ShouldBindJSON(&updateUserRoleDto); err != nil {
+		logger.Error(err)
+		response.NewErrorResponse(c, errors.NewBadRequestError("invalid json"))
+		return
+	}
+
+	if err := h.validator.Struct(updateUserRoleDto); err != nil {
+		logger.Error(err)
+		response.NewErrorResponse(c, errors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	actorID, err := getPayloadUserID(c)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
-	response.NewSuccessResponse(c, nil)
+
+	user, err := h.userService.UpdateUserRole(actorID, id, domain.Role(updateUserRoleDto.Role), getClientInfo(c))
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	userResponse := presenter.User(*user)
+	response.NewSuccessResponse(c, userResponse)
 }
 
-func (h *UserHandler) UpdateUserSession(c *gin.Context) {
+func (h *UserHandler) DeleteUser(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
 		response.NewErrorResponse(c, errors.NewBadRequestError("id is invalid"))
 		return
 	}
-	type RefreshToken struct {
-		RefreshToken *string `json:"refresh_token" validate:"omitempty"`
-	}
-	var body RefreshToken
-	if err := c.ShouldBindJSON(&body); err != nil {
-		logger.Error(err)
+
+	actorID, err := getPayloadUserID(c)
+	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
 
-	if err := h.validator.Struct(body); err != nil {
-		if _, ok := err.(*validator.InvalidValidationError); ok {
-			logger.Error(err)
-			response.NewErrorResponse(c, err)
-			return
-		}
-		logger.Error(err)
-		response.NewErrorResponse(c, errors.NewBadRequestError(err.Error()))
+	if actorID == id && isPersonalAccessToken(c) {
+		response.NewErrorResponse(c, errors.NewForbiddenError("Personal access tokens can't delete their own account"))
 		return
 	}
 
-	err = h.userService.UpdateUserSession(id, body.RefreshToken)
+	err = h.userService.DeleteUser(actorID, id, getClientInfo(c))
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
-
 	response.NewSuccessResponse(c, nil)
 }
 
@@ -254,7 +250,7 @@ func (h *UserHandler) GetUsersWithRelation(c *gin.Context) {
 		response.NewErrorResponse(c, err)
 		return
 	}
-	response.NewSuccessResponse(c, users)
+	response.NewSuccessResponse(c, presenter.UserProfiles(users))
 }
 
 func (h *UserHandler) GetUserWithRelation(c *gin.Context) {
@@ -270,7 +266,7 @@ func (h *UserHandler) GetUserWithRelation(c *gin.Context) {
 		response.NewErrorResponse(c, err)
 		return
 	}
-	response.NewSuccessResponse(c, user)
+	response.NewSuccessResponse(c, presenter.UserProfile(*user))
 }
 
 func (h *UserHandler) GetUserBookmarks(c *gin.Context) {
@@ -281,10 +277,16 @@ func (h *UserHandler) GetUserBookmarks(c *gin.Context) {
 		return
 	}
 
-	bookmarks, err := h.userService.GetUserBookmarks(id)
+	page, err := bindPageRequest(c, h.validator, domain.SortNewest)
+	if err != nil {
+		response.NewErrorResponse(c, err)
+		return
+	}
+
+	bookmarks, err := h.userService.GetUserBookmarks(id, page)
 	if err != nil {
 		response.NewErrorResponse(c, err)
 		return
 	}
-	response.NewSuccessResponse(c, bookmarks)
+	newPageResponse(c, domain.MapPage(bookmarks, presenter.Bookmark))
 }
@@ -0,0 +1,23 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/ppondeu/go-post-api/internal/logger"
+	"go.uber.org/zap"
+)
+
+// Every runs job in the background once per interval for the lifetime of the
+// process. Errors are logged and the job runs again on the next tick.
+func Every(name string, interval time.Duration, job func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := job(); err != nil {
+				logger.Error(err, zap.String("job", name))
+			}
+		}
+	}()
+}
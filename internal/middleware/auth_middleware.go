@@ -3,8 +3,12 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/dto"
 	"github.com/ppondeu/go-post-api/internal/errors"
 	"github.com/ppondeu/go-post-api/internal/response"
 	"github.com/ppondeu/go-post-api/internal/usecase"
@@ -15,10 +19,64 @@ type Payload struct {
 	Token  string
 }
 
-func ValidateAccessToken(jwtService usecase.JwtService) gin.HandlerFunc {
+type TokenTransport string
+
+const (
+	TokenTransportCookie TokenTransport = "cookie"
+	TokenTransportHeader TokenTransport = "header"
+	TokenTransportBoth   TokenTransport = "both"
+)
+
+func (t TokenTransport) Valid() bool {
+	return t == TokenTransportCookie || t == TokenTransportHeader || t == TokenTransportBoth
+}
+
+func (t TokenTransport) AllowsCookie() bool {
+	return t != TokenTransportHeader
+}
+
+func (t TokenTransport) AllowsHeader() bool {
+	return t != TokenTransportCookie
+}
+
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+// ValidateAccessToken reads the access token from the Authorization header
+// and/or the accessToken cookie, depending on transport. When patService is
+// not nil, "Authorization: Bearer pat_..." is validated as a personal access
+// token regardless of transport.
+func ValidateAccessToken(jwtService usecase.JwtService, patService usecase.PersonalAccessTokenService, transport TokenTransport) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenString, err := c.Cookie("accessToken")
-		if err != nil {
+		tokenString := bearerToken(c)
+		if patService != nil && strings.HasPrefix(tokenString, usecase.PersonalAccessTokenPrefix) {
+			claims, err := patService.ValidateToken(tokenString)
+			if err != nil {
+				response.NewErrorResponse(c, err)
+				c.Abort()
+				return
+			}
+
+			c.Set("payload", Payload{
+				Claims: claims,
+				Token:  tokenString,
+			})
+			c.Next()
+			return
+		}
+
+		if !transport.AllowsHeader() {
+			tokenString = ""
+		}
+		if tokenString == "" && transport.AllowsCookie() {
+			tokenString, _ = c.Cookie("accessToken")
+		}
+		if tokenString == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "No access token provided"})
 			c.Abort()
 			return
@@ -31,8 +89,6 @@ func ValidateAccessToken(jwtService usecase.JwtService) gin.HandlerFunc {
 			return
 		}
 
-		fmt.Println(claims)
-
 		payload := Payload{
 			Claims: claims,
 			Token:  tokenString,
@@ -43,10 +99,103 @@ func ValidateAccessToken(jwtService usecase.JwtService) gin.HandlerFunc {
 	}
 }
 
-func ValidateRefreshToken(jwtService usecase.JwtService) gin.HandlerFunc {
+// OptionalAccessToken validates the access token like ValidateAccessToken
+// when the request carries one and lets anonymous requests through without a
+// payload.
+func OptionalAccessToken(jwtService usecase.JwtService, patService usecase.PersonalAccessTokenService, transport TokenTransport) gin.HandlerFunc {
+	validate := ValidateAccessToken(jwtService, patService, transport)
 	return func(c *gin.Context) {
-		tokenString, err := c.Cookie("refreshToken")
-		if err != nil {
+		hasToken := bearerToken(c) != ""
+		if !hasToken && transport.AllowsCookie() {
+			cookie, _ := c.Cookie("accessToken")
+			hasToken = cookie != ""
+		}
+		if !hasToken {
+			c.Next()
+			return
+		}
+		validate(c)
+	}
+}
+
+// RequireScope lets session tokens through and only checks the scopes of
+// personal access tokens.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet("payload").(Payload)
+		if payload.Claims.TokenType != "pat" {
+			c.Next()
+			return
+		}
+
+		for _, s := range payload.Claims.Scopes {
+			if s == scope {
+				c.Next()
+				return
+			}
+		}
+
+		response.NewErrorResponse(c, errors.NewForbiddenError(fmt.Sprintf("Token is missing the %s scope", scope)))
+		c.Abort()
+	}
+}
+
+// RequirePermission checks the role carried in the access token. Personal
+// access tokens carry no role and never pass.
+func RequirePermission(permission domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet("payload").(Payload)
+		if !domain.Role(payload.Claims.Role).HasPermission(permission) {
+			response.NewErrorResponse(c, errors.NewForbiddenError("You don't have permission to perform this action"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// BlockImpersonation rejects impersonation tokens on routes that change
+// credentials, delete accounts or mint tokens.
+func BlockImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		payload := c.MustGet("payload").(Payload)
+		if payload.Claims.ImpersonatorID != "" {
+			response.NewErrorResponse(c, errors.NewForbiddenError("This action is not allowed while impersonating"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func refreshTokenFromBody(c *gin.Context) string {
+	if c.ContentType() != binding.MIMEJSON {
+		return ""
+	}
+
+	var refreshTokenDto dto.RefreshTokenDto
+	if err := c.ShouldBindBodyWith(&refreshTokenDto, binding.JSON); err != nil {
+		return ""
+	}
+	return refreshTokenDto.RefreshToken
+}
+
+// ValidateRefreshToken reads the refresh token from the Authorization header
+// or a {"refreshToken": "..."} JSON body and/or the refreshToken cookie,
+// depending on transport.
+func ValidateRefreshToken(jwtService usecase.JwtService, transport TokenTransport) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var tokenString string
+		if transport.AllowsHeader() {
+			tokenString = bearerToken(c)
+			if tokenString == "" {
+				tokenString = refreshTokenFromBody(c)
+			}
+		}
+		if tokenString == "" && transport.AllowsCookie() {
+			tokenString, _ = c.Cookie("refreshToken")
+		}
+		if tokenString == "" {
 			response.NewErrorResponse(c, errors.NewUnauthorizedError("No refresh token provided"))
 			c.Abort()
 			return
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ppondeu/go-post-api/internal/errors"
+	"github.com/ppondeu/go-post-api/internal/response"
+	"github.com/ppondeu/go-post-api/internal/usecase"
+)
+
+const (
+	CSRFCookieName = "csrfToken"
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func hasAuthCookie(c *gin.Context) bool {
+	for _, name := range []string{"accessToken", "refreshToken"} {
+		if value, err := c.Cookie(name); err == nil && value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func usesBearerToken(c *gin.Context, transport TokenTransport) bool {
+	token := bearerToken(c)
+	if token == "" {
+		return false
+	}
+	return transport.AllowsHeader() || strings.HasPrefix(token, usecase.PersonalAccessTokenPrefix)
+}
+
+// CSRFProtection implements the double-submit pattern: a state-changing
+// request that could be authenticated by cookie must echo the csrfToken
+// cookie in the X-CSRF-Token header. Requests that the Authorization header
+// authenticates instead are not exposed to CSRF and are left alone; under
+// the cookie transport only personal access tokens are read from it.
+func CSRFProtection(transport TokenTransport) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isSafeMethod(c.Request.Method) || usesBearerToken(c, transport) || !hasAuthCookie(c) {
+			c.Next()
+			return
+		}
+
+		cookie, err := c.Cookie(CSRFCookieName)
+		header := c.GetHeader(CSRFHeaderName)
+		if err != nil || cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+			response.NewErrorResponse(c, errors.NewForbiddenError("Invalid CSRF token"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
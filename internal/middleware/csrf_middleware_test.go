@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCSRFProtectionIgnoresBearerOnlyWhenItAuthenticates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		transport     TokenTransport
+		authorization string
+		want          int
+	}{
+		{TokenTransportCookie, "", http.StatusForbidden},
+		{TokenTransportCookie, "Bearer eyJhbGciOi", http.StatusForbidden},
+		{TokenTransportCookie, "Bearer pat_abc", http.StatusOK},
+		{TokenTransportBoth, "Bearer eyJhbGciOi", http.StatusOK},
+	}
+	for _, tt := range tests {
+		router := gin.New()
+		router.Use(CSRFProtection(tt.transport))
+		router.POST("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: "session"})
+		if tt.authorization != "" {
+			req.Header.Set("Authorization", tt.authorization)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("%s transport with %q: status %d, want %d", tt.transport, tt.authorization, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package presenter
+
+import (
+	"time"
+
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/dto"
+)
+
+func User(user domain.User) dto.UserResponseDto {
+	return dto.UserResponseDto{
+		ID:       user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		ShortBio: user.ShortBio,
+		Role:     string(user.Role),
+	}
+}
+
+func Users(users []domain.User) []dto.UserResponseDto {
+	userResponses := make([]dto.UserResponseDto, 0, len(users))
+	for _, user := range users {
+		userResponses = append(userResponses, User(user))
+	}
+	return userResponses
+}
+
+func UserProfile(user domain.User) dto.UserProfileResponse {
+	posts := make([]dto.PostResponse, 0, len(user.Posts))
+	for _, post := range user.Posts {
+		if post.Status != domain.PostStatusPublished {
+			continue
+		}
+		post.User = user
+		posts = append(posts, Post(post))
+	}
+
+	return dto.UserProfileResponse{
+		UserResponseDto: User(user),
+		Posts:           posts,
+		FollowerCount:   len(user.Followed),
+		FollowingCount:  len(user.Follower),
+	}
+}
+
+func UserProfiles(users []domain.User) []dto.UserProfileResponse {
+	userProfiles := make([]dto.UserProfileResponse, 0, len(users))
+	for _, user := range users {
+		userProfiles = append(userProfiles, UserProfile(user))
+	}
+	return userProfiles
+}
+
+func tagNames(tags []domain.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func Post(post domain.Post) dto.PostResponse {
+	return dto.PostResponse{
+		ID:           post.ID,
+		Title:        post.Title,
+		Content:      post.Content,
+		Tags:         tagNames(post.Tags),
+		Author:       dto.Author{ID: post.UserID, Username: post.User.Username},
+		Views:        post.ViewCount,
+		LikeCount:    len(post.Likes),
+		CommentCount: len(post.Comments),
+		Status:       string(post.Status),
+		PublishedAt:  post.PublishedAt,
+		CreatedAt:    post.CreatedAt,
+		UpdatedAt:    post.UpdatedAt,
+	}
+}
+
+func Posts(posts []domain.Post) []dto.PostResponse {
+	postResponses := make([]dto.PostResponse, 0, len(posts))
+	for _, post := range posts {
+		postResponses = append(postResponses, Post(post))
+	}
+	return postResponses
+}
+
+// commentBody hides the content and author of deleted comments, which are
+// only listed as tombstones for their replies.
+func commentBody(comment domain.Comment) (string, dto.Author) {
+	if comment.DeletedAt.Valid {
+		return domain.DeletedCommentContent, dto.Author{}
+	}
+	return comment.Content, dto.Author{ID: comment.UserID, Username: comment.User.Username}
+}
+
+func Comment(comment domain.Comment) dto.CommentResponse {
+	replies := make([]dto.ReplyResponse, 0, len(comment.Replies))
+	for _, reply := range comment.Replies {
+		content, author := commentBody(reply)
+		replies = append(replies, dto.ReplyResponse{
+			ID:        reply.ID,
+			Content:   content,
+			CreatedAt: reply.CreatedAt,
+			UpdatedAt: reply.UpdatedAt,
+			Author:    author,
+			Deleted:   reply.DeletedAt.Valid,
+		})
+	}
+
+	content, author := commentBody(comment)
+	return dto.CommentResponse{
+		ID:        comment.ID,
+		PostID:    comment.PostID,
+		ParentID:  comment.ParentID,
+		Content:   content,
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+		Author:    author,
+		Deleted:   comment.DeletedAt.Valid,
+		Replies:   replies,
+	}
+}
+
+// TrashedComment shows a deleted comment to the user who can restore it.
+func TrashedComment(comment domain.Comment) dto.CommentResponse {
+	return dto.CommentResponse{
+		ID:        comment.ID,
+		PostID:    comment.PostID,
+		ParentID:  comment.ParentID,
+		Content:   comment.Content,
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+		Author:    dto.Author{ID: comment.UserID, Username: comment.User.Username},
+		Deleted:   true,
+		Replies:   []dto.ReplyResponse{},
+	}
+}
+
+func Comments(comments []domain.Comment) []dto.CommentResponse {
+	commentResponses := make([]dto.CommentResponse, 0, len(comments))
+	for _, comment := range comments {
+		commentResponses = append(commentResponses, Comment(comment))
+	}
+	return commentResponses
+}
+
+func Bookmark(bookmark domain.Bookmark) dto.BookmarkResponse {
+	return dto.BookmarkResponse{
+		ID:        bookmark.ID,
+		Post:      Post(bookmark.Post),
+		CreatedAt: bookmark.CreatedAt,
+	}
+}
+
+func Bookmarks(bookmarks []domain.Bookmark) []dto.BookmarkResponse {
+	bookmarkResponses := make([]dto.BookmarkResponse, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		bookmarkResponses = append(bookmarkResponses, Bookmark(bookmark))
+	}
+	return bookmarkResponses
+}
+
+func Tags(tags []domain.Tag) []dto.TagResponse {
+	tagResponses := make([]dto.TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		tagResponses = append(tagResponses, dto.TagResponse{
+			ID:        tag.ID,
+			Name:      tag.Name,
+			Slug:      tag.Slug,
+			PostCount: tag.PostCount,
+		})
+	}
+	return tagResponses
+}
+
+func AuditEvent(event domain.AuditEvent) dto.AuditEventResponseDto {
+	return dto.AuditEventResponseDto{
+		ID:        event.ID,
+		UserID:    event.UserID,
+		ActorID:   event.ActorID,
+		SessionID: event.SessionID,
+		Type:      event.Type,
+		Outcome:   event.Outcome,
+		IP:        event.IP,
+		UserAgent: event.UserAgent,
+		Details:   event.Details,
+		CreatedAt: event.CreatedAt,
+	}
+}
+
+func AuditEvents(events []domain.AuditEvent) []dto.AuditEventResponseDto {
+	eventResponses := make([]dto.AuditEventResponseDto, 0, len(events))
+	for _, event := range events {
+		eventResponses = append(eventResponses, AuditEvent(event))
+	}
+	return eventResponses
+}
+
+// AccountExport expects user loaded with all of its relations.
+func AccountExport(user domain.User) dto.AccountExportDto {
+	export := dto.AccountExportDto{
+		ExportedAt: time.Now(),
+		Profile:    User(user),
+		Posts:      make([]dto.ExportedPostDto, 0, len(user.Posts)),
+		Comments:   make([]dto.ExportedCommentDto, 0, len(user.Comments)),
+		Bookmarks:  make([]dto.ExportedPostRefDto, 0, len(user.Bookmarks)),
+		Likes:      make([]dto.ExportedPostRefDto, 0, len(user.Likes)),
+		Followers:  make([]dto.ExportedFollowDto, 0, len(user.Followed)),
+		Following:  make([]dto.ExportedFollowDto, 0, len(user.Follower)),
+	}
+
+	for _, post := range user.Posts {
+		export.Posts = append(export.Posts, dto.ExportedPostDto{
+			ID:          post.ID,
+			Title:       post.Title,
+			Content:     post.Content,
+			Tags:        tagNames(post.Tags),
+			ViewCount:   post.ViewCount,
+			Status:      string(post.Status),
+			PublishedAt: post.PublishedAt,
+			CreatedAt:   post.CreatedAt,
+			UpdatedAt:   post.UpdatedAt,
+		})
+	}
+	for _, comment := range user.Comments {
+		export.Comments = append(export.Comments, dto.ExportedCommentDto{
+			ID:        comment.ID,
+			PostID:    comment.PostID,
+			ParentID:  comment.ParentID,
+			Content:   comment.Content,
+			CreatedAt: comment.CreatedAt,
+			UpdatedAt: comment.UpdatedAt,
+		})
+	}
+	for _, bookmark := range user.Bookmarks {
+		export.Bookmarks = append(export.Bookmarks, dto.ExportedPostRefDto{PostID: bookmark.PostID, CreatedAt: bookmark.CreatedAt})
+	}
+	for _, like := range user.Likes {
+		export.Likes = append(export.Likes, dto.ExportedPostRefDto{PostID: like.PostID, CreatedAt: like.CreatedAt})
+	}
+	for _, follow := range user.Followed {
+		export.Followers = append(export.Followers, dto.ExportedFollowDto{UserID: follow.FollowerID, CreatedAt: follow.CreatedAt})
+	}
+	for _, follow := range user.Follower {
+		export.Following = append(export.Following, dto.ExportedFollowDto{UserID: follow.FollowedID, CreatedAt: follow.CreatedAt})
+	}
+	return export
+}
+
+func SearchResult(result domain.SearchResult) dto.SearchResultDto {
+	return dto.SearchResultDto{
+		Type:     result.Type,
+		ID:       result.ID,
+		Title:    result.Title,
+		Snippet:  result.Snippet,
+		Rank:     result.Rank,
+		PostID:   result.PostID,
+		AuthorID: result.AuthorID,
+	}
+}
+
+func PostRevision(revision domain.PostRevision) dto.PostRevisionResponseDto {
+	tags := []string(revision.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return dto.PostRevisionResponseDto{
+		Number:    revision.Number,
+		AuthorID:  revision.AuthorID,
+		Title:     revision.Title,
+		Content:   revision.Content,
+		Tags:      tags,
+		CreatedAt: revision.CreatedAt,
+	}
+}
@@ -0,0 +1,191 @@
+package presenter
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/dto"
+	"gorm.io/gorm"
+)
+
+const (
+	leakyPasswordHash    = "$argon2id$v=19$m=65536,t=3,p=2$c2FsdHNhbHRzYWx0c2FsdA$a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+	leakyTwoFactorSecret = "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+	leakyRefreshTokenID  = "9b2f4d1e-7c3a-4e5b-8f6d-1a2b3c4d5e6f"
+)
+
+var (
+	sensitiveKey   = regexp.MustCompile(`(?i)password|hash|secret|token`)
+	hashLikeValues = []*regexp.Regexp{
+		regexp.MustCompile(`^\$(argon2(id|i|d)|2[aby])\$`),
+		regexp.MustCompile(`^[0-9a-fA-F]{64}$`),
+	}
+)
+
+// leakyUser has every secret a user row can carry, reachable through each
+// relation the presenters follow.
+func leakyUser() domain.User {
+	now := time.Now()
+	secret := leakyTwoFactorSecret
+	refreshTokenID := leakyRefreshTokenID
+	parentID := "c0000000-0000-0000-0000-000000000001"
+
+	user := domain.User{
+		ID:                "u0000000-0000-0000-0000-000000000001",
+		Username:          "alice",
+		Email:             "alice@example.com",
+		EmailVerified:     true,
+		Password:          leakyPasswordHash,
+		Role:              domain.RoleAdmin,
+		TwoFactorEnabled:  true,
+		TwoFactorSecret:   &secret,
+		TwoFactorLastStep: 42,
+		UserSessions:      []domain.UserSession{{ID: "s1", UserID: "u1", RefreshTokenID: &refreshTokenID}},
+	}
+	author := user
+
+	comment := domain.Comment{
+		ID:      parentID,
+		PostID:  "p1",
+		UserID:  user.ID,
+		User:    author,
+		Content: "first",
+		Replies: []domain.Comment{
+			{ID: "c2", PostID: "p1", ParentID: &parentID, UserID: user.ID, User: author, Content: "reply"},
+			{ID: "c3", PostID: "p1", ParentID: &parentID, UserID: user.ID, User: author, Content: "gone",
+				DeletedAt: gorm.DeletedAt{Time: now, Valid: true}},
+		},
+	}
+	post := domain.Post{
+		ID:          "p1",
+		UserID:      user.ID,
+		User:        author,
+		Title:       "title",
+		Content:     "content",
+		Tags:        []domain.Tag{{ID: "t1", Name: "go", Slug: "go"}},
+		Status:      domain.PostStatusPublished,
+		PublishedAt: &now,
+		Comments:    []domain.Comment{comment},
+		Likes:       []domain.Like{{UserID: user.ID, PostID: "p1"}},
+	}
+
+	user.Posts = []domain.Post{post}
+	user.Comments = []domain.Comment{comment}
+	user.Likes = post.Likes
+	user.Bookmarks = []domain.Bookmark{{ID: "b1", UserID: user.ID, PostID: post.ID, Post: post}}
+	user.Follower = []domain.Follow{{FollowerID: user.ID, FollowedID: "u2"}}
+	user.Followed = []domain.Follow{{FollowerID: "u3", FollowedID: user.ID}}
+	return user
+}
+
+// findSecrets lists every password, hash or token key in the JSON rendering
+// of body, and every value that looks like a password or token hash.
+func findSecrets(body interface{}) ([]string, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+
+	var found []string
+	var walk func(path string, value interface{})
+	walk = func(path string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, child := range v {
+				if sensitiveKey.MatchString(key) {
+					found = append(found, "sensitive key "+path+"."+key)
+				}
+				walk(path+"."+key, child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(path+"[]", child)
+			}
+		case string:
+			switch v {
+			case leakyPasswordHash, leakyTwoFactorSecret, leakyRefreshTokenID:
+				found = append(found, "secret value at "+path)
+				return
+			}
+			for _, pattern := range hashLikeValues {
+				if pattern.MatchString(v) {
+					found = append(found, "hash-like value at "+path)
+				}
+			}
+		}
+	}
+	walk("$", decoded)
+	return found, nil
+}
+
+func TestResponsesNeverContainSecrets(t *testing.T) {
+	user := leakyUser()
+	post := user.Posts[0]
+	comment := user.Comments[0]
+	userID := user.ID
+
+	responses := map[string]interface{}{
+		"User":           User(user),
+		"Users":          Users([]domain.User{user}),
+		"UserProfile":    UserProfile(user),
+		"UserProfiles":   UserProfiles([]domain.User{user}),
+		"Post":           Post(post),
+		"Posts":          Posts(user.Posts),
+		"Comment":        Comment(comment),
+		"Comments":       Comments(user.Comments),
+		"TrashedComment": TrashedComment(comment),
+		"Bookmark":       Bookmark(user.Bookmarks[0]),
+		"Bookmarks":      Bookmarks(user.Bookmarks),
+		"Tags":           Tags(post.Tags),
+		"AuditEvent": AuditEvent(domain.AuditEvent{
+			ID: "e1", UserID: &userID, ActorID: &userID, Type: domain.AuditEventPasswordChanged, Outcome: domain.AuditOutcomeSuccess,
+		}),
+		"AuditEvents":   AuditEvents([]domain.AuditEvent{{ID: "e2", UserID: &userID}}),
+		"AccountExport": AccountExport(user),
+		"SearchResult":  SearchResult(domain.SearchResult{Type: domain.SearchTypeUser, ID: user.ID, Title: user.Username}),
+		"PostRevision": PostRevision(domain.PostRevision{
+			Number: 1, AuthorID: user.ID, Title: post.Title, Content: post.Content, Tags: pq.StringArray{"go"},
+		}),
+		// built by the services rather than a presenter
+		"Session": dto.SessionResponseDto{ID: user.UserSessions[0].ID, UserAgent: "curl", IP: "127.0.0.1"},
+		"PersonalAccessToken": dto.PersonalAccessTokenResponseDto{
+			ID: "pat1", Name: "ci", Prefix: "gpa_1234", Scopes: []string{domain.ScopePostsWrite},
+		},
+	}
+	for name, body := range responses {
+		found, err := findSecrets(body)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for _, secret := range found {
+			t.Errorf("%s: %s", name, secret)
+		}
+	}
+}
+
+// The check itself must catch what it is meant to catch.
+func TestFindSecretsDetectsLeaks(t *testing.T) {
+	leaks := map[string]interface{}{
+		"sensitive key": map[string]string{"refreshToken": "x"},
+		"password hash": map[string]string{"value": leakyPasswordHash},
+		"bcrypt value":  map[string]string{"value": "$2a$10$abcdefghijklmnopqrstuv"},
+		"sha256 value":  []string{"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"},
+	}
+	for name, body := range leaks {
+		found, err := findSecrets(body)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(found) == 0 {
+			t.Errorf("%s: leak was not detected", name)
+		}
+	}
+}
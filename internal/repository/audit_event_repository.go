@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+type AuditEventRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewAuditEventRepositoryDB(db *gorm.DB) domain.AuditEventRepository {
+	return &AuditEventRepositoryDB{db}
+}
+
+func (r *AuditEventRepositoryDB) Create(event *domain.AuditEvent) error {
+	if err := r.db.Create(event).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *AuditEventRepositoryDB) Find(filter domain.AuditEventFilter, page domain.PageRequest) (*domain.Page[domain.AuditEvent], error) {
+	query := r.db.Model(&domain.AuditEvent{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	key := sortKey{table: "audit_events", column: "created_at", cast: "timestamp", desc: true}
+	var events []domain.AuditEvent
+	if err := keysetPage(query, key, page).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return pageOf(events, page, func(event domain.AuditEvent) domain.Cursor {
+		return domain.Cursor{Value: cursorTime(event.CreatedAt), ID: event.ID}
+	}), nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExternalIdentityRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewExternalIdentityRepositoryDB(db *gorm.DB) domain.ExternalIdentityRepository {
+	return &ExternalIdentityRepositoryDB{db}
+}
+
+func (r *ExternalIdentityRepositoryDB) Create(identity *domain.ExternalIdentity) error {
+	if err := r.db.Create(identity).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ExternalIdentityRepositoryDB) FindByProviderSubject(provider, subject string) (*domain.ExternalIdentity, error) {
+	var identity domain.ExternalIdentity
+	if err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *ExternalIdentityRepositoryDB) FindByUserID(userID uuid.UUID) ([]domain.ExternalIdentity, error) {
+	var identities []domain.ExternalIdentity
+	if err := r.db.Where("user_id = ?", userID).Order("created_at").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (r *ExternalIdentityRepositoryDB) CreateLoginState(state *domain.OIDCLoginState) error {
+	if err := r.db.Create(state).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ExternalIdentityRepositoryDB) ConsumeLoginState(stateHash string) (*domain.OIDCLoginState, error) {
+	var states []domain.OIDCLoginState
+	result := r.db.Clauses(clause.Returning{}).Where("state_hash = ?", stateHash).Delete(&states)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(states) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &states[0], nil
+}
+
+func (r *ExternalIdentityRepositoryDB) DeleteExpiredLoginStates() error {
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&domain.OIDCLoginState{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -52,18 +52,24 @@ func (r *FollowRepositoryDB) Delete(ID uuid.UUID) error {
 	return nil
 }
 
-func (r *FollowRepositoryDB) FindFollowersByUserID(userID uuid.UUID) ([]domain.Follow, error) {
+var followSortKey = sortKey{table: "follows", column: "created_at", cast: "timestamp", desc: true}
+
+func followCursor(follow domain.Follow) domain.Cursor {
+	return domain.Cursor{Value: cursorTime(follow.CreatedAt), ID: follow.ID}
+}
+
+func (r *FollowRepositoryDB) FindFollowersByUserID(userID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.Follow], error) {
 	var Follows []domain.Follow
-	if err := r.db.Where("followed_id = ?", userID).Find(&Follows).Error; err != nil {
+	if err := keysetPage(r.db.Where("followed_id = ?", userID), followSortKey, page).Find(&Follows).Error; err != nil {
 		return nil, err
 	}
-	return Follows, nil
+	return pageOf(Follows, page, followCursor), nil
 }
 
-func (r *FollowRepositoryDB) FindFollowedUsersByUserID(userID uuid.UUID) ([]domain.Follow, error) {
+func (r *FollowRepositoryDB) FindFollowedUsersByUserID(userID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.Follow], error) {
 	var Follows []domain.Follow
-	if err := r.db.Where("follower_id = ?", userID).Find(&Follows).Error; err != nil {
+	if err := keysetPage(r.db.Where("follower_id = ?", userID), followSortKey, page).Find(&Follows).Error; err != nil {
 		return nil, err
 	}
-	return Follows, nil
+	return pageOf(Follows, page, followCursor), nil
 }
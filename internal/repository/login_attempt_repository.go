@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAttemptRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepositoryDB(db *gorm.DB) domain.LoginAttemptRepository {
+	return &LoginAttemptRepositoryDB{db}
+}
+
+func (r *LoginAttemptRepositoryDB) FindByKey(key string) (*domain.LoginAttempt, error) {
+	var attempt domain.LoginAttempt
+	if err := r.db.Where("key = ?", key).First(&attempt).Error; err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (r *LoginAttemptRepositoryDB) Save(attempt *domain.LoginAttempt) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		UpdateAll: true,
+	}).Create(attempt).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *LoginAttemptRepositoryDB) Delete(key string) error {
+	if err := r.db.Where("key = ?", key).Delete(&domain.LoginAttempt{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// sortKey is a column that, together with the table's id, gives a stable
+// order for keyset pagination.
+type sortKey struct {
+	table  string
+	column string
+	// SQL type the cursor value is cast to before comparing
+	cast string
+	desc bool
+}
+
+// keysetPage orders query by key and fetches one row past page.Limit so
+// pageOf can tell whether there is more.
+func keysetPage(query *gorm.DB, key sortKey, page domain.PageRequest) *gorm.DB {
+	op, dir := ">", "ASC"
+	if key.desc {
+		op, dir = "<", "DESC"
+	}
+
+	if page.After != nil {
+		query = query.Where(
+			fmt.Sprintf("(%s.%s, %s.id) %s (CAST(? AS %s), CAST(? AS uuid))", key.table, key.column, key.table, op, key.cast),
+			page.After.Value, page.After.ID,
+		)
+	}
+	return query.
+		Order(fmt.Sprintf("%s.%s %s, %s.id %s", key.table, key.column, dir, key.table, dir)).
+		Limit(page.Limit + 1)
+}
+
+func pageOf[T any](items []T, page domain.PageRequest, cursor func(T) domain.Cursor) *domain.Page[T] {
+	result := &domain.Page[T]{Items: items, Limit: page.Limit}
+	if len(items) > page.Limit {
+		result.Items = items[:page.Limit]
+		result.HasMore = true
+
+		next := cursor(result.Items[page.Limit-1])
+		next.Sort = page.Sort
+		result.NextCursor = &next
+	}
+	return result
+}
+
+func cursorTime(t time.Time) string {
+	return t.Format(domain.CursorTimeLayout)
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+type PersonalAccessTokenRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewPersonalAccessTokenRepositoryDB(db *gorm.DB) domain.PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepositoryDB{db}
+}
+
+func (r *PersonalAccessTokenRepositoryDB) Create(token *domain.PersonalAccessToken) error {
+	if err := r.db.Create(token).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *PersonalAccessTokenRepositoryDB) FindByID(ID uuid.UUID) (*domain.PersonalAccessToken, error) {
+	var token domain.PersonalAccessToken
+	if err := r.db.Where("id = ?", ID).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *PersonalAccessTokenRepositoryDB) FindByHash(tokenHash string) (*domain.PersonalAccessToken, error) {
+	var token domain.PersonalAccessToken
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *PersonalAccessTokenRepositoryDB) FindByUserID(userID uuid.UUID) ([]domain.PersonalAccessToken, error) {
+	var tokens []domain.PersonalAccessToken
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *PersonalAccessTokenRepositoryDB) UpdateLastUsed(ID uuid.UUID) error {
+	err := r.db.Model(&domain.PersonalAccessToken{}).Where("id = ?", ID).Update("last_used_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *PersonalAccessTokenRepositoryDB) Delete(ID uuid.UUID) error {
+	if err := r.db.Where("id = ?", ID).Delete(&domain.PersonalAccessToken{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
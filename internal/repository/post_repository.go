@@ -1,6 +1,10 @@
 package repository
 
 import (
+	"fmt"
+	"strconv"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/ppondeu/go-post-api/internal/domain"
 	"github.com/ppondeu/go-post-api/internal/logger"
@@ -16,59 +20,160 @@ func NewPostRepositoryDB(db *gorm.DB) domain.PostRepository {
 	return &PostRepositoryDB{db}
 }
 
-func (r *PostRepositoryDB) FindAll() ([]domain.Post, error) {
+func selectAuthor(db *gorm.DB) *gorm.DB {
+	return db.Select("id, username")
+}
+
+// activeAuthors hides the posts and comments of accounts pending deletion.
+func activeAuthors(db *gorm.DB) *gorm.DB {
+	return db.Where("user_id NOT IN (SELECT id FROM users WHERE deletion_due_at IS NOT NULL)")
+}
+
+// tombstones keeps deleted comments that still have live replies. They are
+// shown as "[deleted]" so the thread stays readable; use it with Unscoped.
+const tombstones = "comments.deleted_at IS NULL OR EXISTS (SELECT 1 FROM comments AS replies WHERE replies.parent_id = comments.id AND replies.deleted_at IS NULL)"
+
+// taggedWith matches posts carrying the tag with the given slug.
+const taggedWith = "posts.id IN (SELECT post_tags.post_id FROM post_tags JOIN tags ON tags.id = post_tags.tag_id WHERE tags.slug = ?)"
+
+// selectPostRef loads only what the post listing needs to count likes and
+// comments.
+func selectPostRef(db *gorm.DB) *gorm.DB {
+	return db.Select("id, post_id")
+}
+
+var postSortKeys = map[string]sortKey{
+	domain.SortNewest:  {table: "posts", column: "created_at", cast: "timestamp", desc: true},
+	domain.SortOldest:  {table: "posts", column: "created_at", cast: "timestamp"},
+	domain.SortPopular: {table: "posts", column: "view_count", cast: "integer", desc: true},
+}
+
+// postDateColumn is the column published feeds are dated by, which is when
+// their posts went live rather than when they were written.
+func postDateColumn(filter domain.PostFilter) string {
+	if filter.Status == domain.PostStatusPublished {
+		return "published_at"
+	}
+	return "created_at"
+}
+
+func postCursor(sort, dateColumn string) func(domain.Post) domain.Cursor {
+	return func(post domain.Post) domain.Cursor {
+		if sort == domain.SortPopular {
+			return domain.Cursor{Value: strconv.Itoa(post.ViewCount), ID: post.ID}
+		}
+		if dateColumn == "published_at" && post.PublishedAt != nil {
+			return domain.Cursor{Value: cursorTime(*post.PublishedAt), ID: post.ID}
+		}
+		return domain.Cursor{Value: cursorTime(post.CreatedAt), ID: post.ID}
+	}
+}
+
+func (r *PostRepositoryDB) FindAll(filter domain.PostFilter, page domain.PageRequest) (*domain.Page[domain.Post], error) {
+	key, ok := postSortKeys[page.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown post sort %q", page.Sort)
+	}
+	dateColumn := postDateColumn(filter)
+	if key.column == "created_at" {
+		key.column = dateColumn
+	}
+
+	query := r.db.
+		Preload("User", selectAuthor).
+		Preload("Tags").
+		Preload("Likes", selectPostRef).
+		Preload("Comments", func(db *gorm.DB) *gorm.DB { return selectPostRef(activeAuthors(db)) }).
+		Scopes(activeAuthors)
+	if filter.AuthorID != nil {
+		query = query.Where("posts.user_id = ?", *filter.AuthorID)
+	}
+	if filter.Status != "" {
+		query = query.Where("posts.status = ?", filter.Status)
+	}
+	if filter.Tag != "" {
+		query = query.Where(taggedWith, filter.Tag)
+	}
+	if filter.From != nil {
+		query = query.Where("posts."+dateColumn+" >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("posts."+dateColumn+" < ?", *filter.To)
+	}
+
 	var posts []domain.Post
-	err := r.db.Preload(clause.Associations).Find(&posts).Error
+	err := keysetPage(query, key, page).Find(&posts).Error
 	if err != nil {
 		return nil, err
 	}
-	return posts, nil
+	return pageOf(posts, page, postCursor(page.Sort, dateColumn)), nil
 }
 
 func (r *PostRepositoryDB) FindByID(ID uuid.UUID) (*domain.Post, error) {
 	var post domain.Post
-	result := r.db.Preload("User").Preload("Likes").Preload("Comments").First(&post, ID)
+	result := r.db.Preload("User").Preload("Tags").Preload("Likes").Preload("Comments", activeAuthors).Scopes(activeAuthors).First(&post, ID)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &post, nil
 }
 
-func (r *PostRepositoryDB) FindByUserID(userID uuid.UUID) ([]domain.Post, error) {
-	var posts []domain.Post
-	result := r.db.Preload("User").Preload("Likes").Preload("Comments").Where("user_id = ?", userID).Find(&posts)
-	if result.Error != nil {
-		return nil, result.Error
-	}
-	return posts, nil
-}
-
 func (r *PostRepositoryDB) Save(post domain.Post) (*domain.Post, error) {
-	err := r.db.Create(&post).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&post).Error; err != nil {
+			return err
+		}
+		return snapshotPost(tx, post.ID)
+	})
 	if err != nil {
 		return nil, err
 	}
 	var savedPost domain.Post
 	ID, _ := uuid.Parse(post.ID)
-	err = r.db.Preload("User", func(db *gorm.DB) *gorm.DB {
-		return db.Select("id, username")
-	}).Preload("Likes").Preload("Comments").First(&savedPost, ID).Error
+	err = r.db.Preload("User", selectAuthor).Preload("Tags").Preload("Likes").Preload("Comments").First(&savedPost, ID).Error
 	if err != nil {
 		return nil, err
 	}
 	return &savedPost, nil
 }
 
+// Update replaces the post's tags with post.Tags unless it is nil and records
+// the result as a new revision. A post.Status is written together with
+// post.PublishedAt, so a nil PublishedAt clears it.
 func (r *PostRepositoryDB) Update(ID uuid.UUID, post domain.Post) (*domain.Post, error) {
-	err := r.db.Model(&domain.Post{}).Where("id = ?", ID).Updates(post).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// posts from before revisions existed get their original state first
+		var revisions int64
+		if err := tx.Model(&domain.PostRevision{}).Where("post_id = ?", ID).Count(&revisions).Error; err != nil {
+			return err
+		}
+		if revisions == 0 {
+			if err := snapshotPost(tx, ID.String()); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&domain.Post{}).Where("id = ?", ID).Omit("Tags", "Status", "PublishedAt").Updates(post).Error; err != nil {
+			return err
+		}
+		if post.Status != "" {
+			if err := setStatus(tx, ID, post.Status, post.PublishedAt); err != nil {
+				return err
+			}
+		}
+		if post.Tags != nil {
+			if err := tx.Model(&domain.Post{ID: ID.String()}).Association("Tags").Replace(post.Tags); err != nil {
+				return err
+			}
+		}
+		return snapshotPost(tx, ID.String())
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	var updatedPost domain.Post
-	err = r.db.Preload("User", func(db *gorm.DB) *gorm.DB {
-		return db.Select("id, username")
-	}).Preload("Likes").Preload("Comments").First(&updatedPost, ID).Error
+	err = r.db.Preload("User", selectAuthor).Preload("Tags").Preload("Likes").Preload("Comments").First(&updatedPost, ID).Error
 
 	if err != nil {
 		return nil, err
@@ -76,23 +181,142 @@ func (r *PostRepositoryDB) Update(ID uuid.UUID, post domain.Post) (*domain.Post,
 	return &updatedPost, nil
 }
 
-func (r *PostRepositoryDB) Delete(ID uuid.UUID) error {
-	result := r.db.Delete(&domain.Post{}, ID)
+func (r *PostRepositoryDB) Delete(ID, deletedBy uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Post{}).Where("id = ?", ID).Update("deleted_by", deletedBy).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Post{}, ID).Error
+	})
+}
+
+func (r *PostRepositoryDB) FindDeleted(deletedBy uuid.UUID, page domain.PageRequest) (*domain.Page[domain.Post], error) {
+	key := sortKey{table: "posts", column: "deleted_at", cast: "timestamp", desc: true}
+	query := r.db.Unscoped().
+		Preload("User", selectAuthor).
+		Preload("Tags").
+		Where("posts.deleted_at IS NOT NULL AND posts.deleted_by = ?", deletedBy)
+
+	var posts []domain.Post
+	if err := keysetPage(query, key, page).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	return pageOf(posts, page, func(post domain.Post) domain.Cursor {
+		return domain.Cursor{Value: cursorTime(post.DeletedAt.Time), ID: post.ID}
+	}), nil
+}
+
+func (r *PostRepositoryDB) FindDeletedByID(ID uuid.UUID) (*domain.Post, error) {
+	var post domain.Post
+	result := r.db.Unscoped().Preload("User", selectAuthor).Preload("Tags").Where("deleted_at IS NOT NULL").First(&post, ID)
 	if result.Error != nil {
-		return result.Error
+		return nil, result.Error
+	}
+	return &post, nil
+}
+
+func (r *PostRepositoryDB) Restore(ID uuid.UUID) error {
+	err := r.db.Unscoped().Model(&domain.Post{}).Where("id = ?", ID).Updates(map[string]interface{}{
+		"deleted_at": nil,
+		"deleted_by": nil,
+	}).Error
+	if err != nil {
+		return err
 	}
 	return nil
 }
 
+// PurgeDeleted keeps trashed comments that still have replies; they go once
+// their replies are gone. Purging a post cascades to everything on it.
+func (r *PostRepositoryDB) PurgeDeleted(before time.Time) (int64, error) {
+	var purged int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().
+			Where("deleted_at < ? AND NOT EXISTS (SELECT 1 FROM comments AS replies WHERE replies.parent_id = comments.id)", before).
+			Delete(&domain.Comment{})
+		if result.Error != nil {
+			return result.Error
+		}
+		purged += result.RowsAffected
+
+		result = tx.Unscoped().Where("deleted_at < ?", before).Delete(&domain.Post{})
+		if result.Error != nil {
+			return result.Error
+		}
+		purged += result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+func (r *PostRepositoryDB) SetStatus(ID uuid.UUID, status domain.PostStatus, publishedAt *time.Time) error {
+	return setStatus(r.db, ID, status, publishedAt)
+}
+
+func setStatus(db *gorm.DB, ID uuid.UUID, status domain.PostStatus, publishedAt *time.Time) error {
+	return db.Model(&domain.Post{}).Where("id = ?", ID).Updates(map[string]interface{}{
+		"status":       status,
+		"published_at": publishedAt,
+	}).Error
+}
+
+func (r *PostRepositoryDB) PublishDue(now time.Time) (int64, error) {
+	result := r.db.Model(&domain.Post{}).
+		Where("status = ? AND published_at <= ?", domain.PostStatusScheduled, now).
+		Update("status", domain.PostStatusPublished)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
 func (r *PostRepositoryDB) FindAllTags() ([]domain.Tag, error) {
 	var tags []domain.Tag
-	result := r.db.Find(&tags)
+	result := r.db.Model(&domain.Tag{}).
+		Select("tags.*, COUNT(post_tags.post_id) AS post_count").
+		Joins("LEFT JOIN post_tags ON post_tags.tag_id = tags.id AND post_tags.post_id IN (SELECT id FROM posts WHERE status = ? AND deleted_at IS NULL)", domain.PostStatusPublished).
+		Group("tags.id").
+		Order("post_count DESC, tags.name").
+		Find(&tags)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return tags, nil
 }
 
+func (r *PostRepositoryDB) FindTagBySlug(slug string) (*domain.Tag, error) {
+	var tag domain.Tag
+	result := r.db.Where("slug = ?", slug).First(&tag)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &tag, nil
+}
+
+func (r *PostRepositoryDB) FindOrCreateTags(tags []domain.Tag) ([]domain.Tag, error) {
+	if len(tags) == 0 {
+		return []domain.Tag{}, nil
+	}
+
+	err := r.db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "slug"}}, DoNothing: true}).Create(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+
+	slugs := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		slugs = append(slugs, tag.Slug)
+	}
+	var stored []domain.Tag
+	if err := r.db.Where("slug IN ?", slugs).Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
 func (r *PostRepositoryDB) AddBookmark(bookmark domain.Bookmark) error {
 	result := r.db.Create(&bookmark)
 	if result.Error != nil {
@@ -109,14 +333,6 @@ func (r *PostRepositoryDB) RemoveBookmark(userID, postID uuid.UUID) error {
 	return nil
 }
 
-func (r *PostRepositoryDB) CreateTag(tag domain.Tag) (*domain.Tag, error) {
-	err := r.db.Create(&tag).Error
-	if err != nil {
-		return nil, err
-	}
-	return &tag, nil
-}
-
 func (r *PostRepositoryDB) LikePost(like domain.Like) error {
 	err := r.db.Create(&like).Error
 	if err != nil {
@@ -152,9 +368,7 @@ func (r *PostRepositoryDB) AddComment(comment domain.Comment) (*domain.Comment,
 
 	var savedComment domain.Comment
 	ID, _ := uuid.Parse(comment.ID)
-	err = r.db.Preload("Replies", func(db *gorm.DB) *gorm.DB {
-		return db.Select("id, content, user_id")
-	}).First(&savedComment, ID).Error
+	err = r.db.Preload("User", selectAuthor).Preload("Replies.User", selectAuthor).First(&savedComment, ID).Error
 	if err != nil {
 		logger.Error(err)
 		return nil, err
@@ -169,9 +383,7 @@ func (r *PostRepositoryDB) UpdateComment(ID uuid.UUID, comment domain.Comment) (
 	}
 
 	var updatedComment domain.Comment
-	err = r.db.Preload("Replies", func(db *gorm.DB) *gorm.DB {
-		return db.Select("id, content, user_id, post_id, parent_id")
-	}).First(&updatedComment, ID).Error
+	err = r.db.Preload("User", selectAuthor).Preload("Replies.User", selectAuthor).First(&updatedComment, ID).Error
 	if err != nil {
 		return nil, err
 	}
@@ -179,28 +391,67 @@ func (r *PostRepositoryDB) UpdateComment(ID uuid.UUID, comment domain.Comment) (
 	return &updatedComment, nil
 }
 
-func (r *PostRepositoryDB) DeleteComment(ID uuid.UUID) error {
-	result := r.db.Delete(&domain.Comment{}, ID)
+func (r *PostRepositoryDB) DeleteComment(ID, deletedBy uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Comment{}).Where("id = ?", ID).Update("deleted_by", deletedBy).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Comment{}, ID).Error
+	})
+}
+
+func (r *PostRepositoryDB) FindDeletedComments(deletedBy uuid.UUID, page domain.PageRequest) (*domain.Page[domain.Comment], error) {
+	key := sortKey{table: "comments", column: "deleted_at", cast: "timestamp", desc: true}
+	query := r.db.Unscoped().
+		Preload("User", selectAuthor).
+		Where("comments.deleted_at IS NOT NULL AND comments.deleted_by = ?", deletedBy)
+
+	var comments []domain.Comment
+	if err := keysetPage(query, key, page).Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	return pageOf(comments, page, func(comment domain.Comment) domain.Cursor {
+		return domain.Cursor{Value: cursorTime(comment.DeletedAt.Time), ID: comment.ID}
+	}), nil
+}
+
+func (r *PostRepositoryDB) FindDeletedCommentByID(ID uuid.UUID) (*domain.Comment, error) {
+	var comment domain.Comment
+	result := r.db.Unscoped().Preload("User", selectAuthor).Where("deleted_at IS NOT NULL").First(&comment, ID)
 	if result.Error != nil {
-		return result.Error
+		return nil, result.Error
+	}
+	return &comment, nil
+}
+
+func (r *PostRepositoryDB) RestoreComment(ID uuid.UUID) error {
+	err := r.db.Unscoped().Model(&domain.Comment{}).Where("id = ?", ID).Updates(map[string]interface{}{
+		"deleted_at": nil,
+		"deleted_by": nil,
+	}).Error
+	if err != nil {
+		return err
 	}
 	return nil
 }
 
-func (r *PostRepositoryDB) FindCommentsByPostID(postID uuid.UUID) ([]domain.Comment, error) {
+func (r *PostRepositoryDB) FindCommentsByPostID(postID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.Comment], error) {
+	key := sortKey{table: "comments", column: "created_at", cast: "timestamp"}
+	query := r.db.Unscoped().Preload("User", selectAuthor).Scopes(activeAuthors).Where("post_id = ?", postID).Where(tombstones)
+
 	var comments []domain.Comment
-	result := r.db.Where("post_id = ?", postID).Find(&comments)
+	result := keysetPage(query, key, page).Find(&comments)
 	if result.Error != nil {
 		return nil, result.Error
 	}
-	return comments, nil
+	return pageOf(comments, page, func(comment domain.Comment) domain.Cursor {
+		return domain.Cursor{Value: cursorTime(comment.CreatedAt), ID: comment.ID}
+	}), nil
 }
 
 func (r *PostRepositoryDB) FindCommentByID(ID uuid.UUID) (*domain.Comment, error) {
 	var comment domain.Comment
-	result := r.db.Preload("Replies", func(db *gorm.DB) *gorm.DB {
-		return db.Select("id, content, user_id, post_id, parent_id")
-	}).First(&comment, ID)
+	result := r.db.Preload("User", selectAuthor).Preload("Replies.User", selectAuthor).First(&comment, ID)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -209,7 +460,7 @@ func (r *PostRepositoryDB) FindCommentByID(ID uuid.UUID) (*domain.Comment, error
 
 func (r *PostRepositoryDB) FindRepliesByCommentID(commentID uuid.UUID) ([]domain.Comment, error) {
 	var comments []domain.Comment
-	result := r.db.Select("id, content, user_id, post_id, parent_id").Where("parent_id = ?", commentID).Find(&comments)
+	result := r.db.Unscoped().Preload("User", selectAuthor).Scopes(activeAuthors).Where("parent_id = ?", commentID).Where(tombstones).Find(&comments)
 	if result.Error != nil {
 		return nil, result.Error
 	}
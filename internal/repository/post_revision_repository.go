@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PostRevisionRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewPostRevisionRepositoryDB(db *gorm.DB) domain.PostRevisionRepository {
+	return &PostRevisionRepositoryDB{db}
+}
+
+// snapshotPost stores the current state of the post as its next revision. The
+// post row stays locked until tx ends so concurrent updates number their
+// revisions in order.
+func snapshotPost(tx *gorm.DB, postID string) error {
+	var post domain.Post
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Tags").First(&post, "id = ?", postID).Error
+	if err != nil {
+		return err
+	}
+
+	var last int
+	err = tx.Model(&domain.PostRevision{}).Where("post_id = ?", postID).Select("COALESCE(MAX(number), 0)").Scan(&last).Error
+	if err != nil {
+		return err
+	}
+
+	tags := make(pq.StringArray, 0, len(post.Tags))
+	for _, tag := range post.Tags {
+		tags = append(tags, tag.Name)
+	}
+	return tx.Create(&domain.PostRevision{
+		PostID:   post.ID,
+		Number:   last + 1,
+		AuthorID: post.UserID,
+		Title:    post.Title,
+		Content:  post.Content,
+		Tags:     tags,
+	}).Error
+}
+
+func (r *PostRevisionRepositoryDB) FindByPostID(postID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.PostRevision], error) {
+	key := sortKey{table: "post_revisions", column: "number", cast: "integer", desc: true}
+	query := r.db.Where("post_id = ?", postID)
+
+	var revisions []domain.PostRevision
+	if err := keysetPage(query, key, page).Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return pageOf(revisions, page, func(revision domain.PostRevision) domain.Cursor {
+		return domain.Cursor{Value: strconv.Itoa(revision.Number), ID: revision.ID}
+	}), nil
+}
+
+func (r *PostRevisionRepositoryDB) FindByNumber(postID uuid.UUID, number int) (*domain.PostRevision, error) {
+	var revision domain.PostRevision
+	if err := r.db.Where("post_id = ? AND number = ?", postID, number).First(&revision).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
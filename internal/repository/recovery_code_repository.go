@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepositoryDB(db *gorm.DB) domain.RecoveryCodeRepository {
+	return &RecoveryCodeRepositoryDB{db}
+}
+
+func (r *RecoveryCodeRepositoryDB) Replace(userID uuid.UUID, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]domain.RecoveryCode, 0, len(codeHashes))
+		for _, codeHash := range codeHashes {
+			codes = append(codes, domain.RecoveryCode{
+				UserID:   userID.String(),
+				CodeHash: codeHash,
+			})
+		}
+		if err := tx.Create(&codes).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *RecoveryCodeRepositoryDB) Use(userID uuid.UUID, codeHash string) (bool, error) {
+	result := r.db.Model(&domain.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *RecoveryCodeRepositoryDB) DeleteByUserID(userID uuid.UUID) error {
+	if err := r.db.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
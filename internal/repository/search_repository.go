@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+const headlineOptions = "MaxFragments=2, MaxWords=30, MinWords=10, StartSel=<mark>, StopSel=</mark>"
+
+// escapedBody HTML-escapes the body, so the <mark> tags ts_headline adds are
+// the only markup in a snippet.
+const escapedBody = `replace(replace(replace(replace(replace(coalesce(search.body, ''),
+	'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+
+type SearchRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewSearchRepositoryDB(db *gorm.DB) domain.SearchRepository {
+	return &SearchRepositoryDB{db}
+}
+
+// tsquery turns the parsed terms into a single tsquery expression.
+func tsquery(terms []domain.SearchTerm) (string, []interface{}) {
+	parts := make([]string, 0, len(terms))
+	args := make([]interface{}, 0, len(terms))
+	for _, term := range terms {
+		switch {
+		case term.Phrase:
+			parts = append(parts, "phraseto_tsquery('english', ?)")
+			args = append(args, term.Text)
+		case term.Prefix:
+			parts = append(parts, "to_tsquery('english', ?)")
+			args = append(args, term.Text+":*")
+		default:
+			parts = append(parts, "plainto_tsquery('english', ?)")
+			args = append(args, term.Text)
+		}
+	}
+	return strings.Join(parts, " && "), args
+}
+
+// searchSources builds one SELECT per searched type. Every branch returns the
+// same columns so they can be ranked together.
+func searchSources(query domain.SearchQuery) ([]string, []interface{}) {
+	wants := func(searchType string) bool {
+		return len(query.Types) == 0 || slices.Contains(query.Types, searchType)
+	}
+
+	var sources []string
+	var args []interface{}
+	if wants(domain.SearchTypePost) {
+		sql := `SELECT 'post' AS type, posts.id, posts.title, posts.content AS body,
+			ts_rank(posts.search_vector, q.query) AS rank, posts.id AS post_id, posts.user_id AS author_id, q.query
+			FROM posts, q
+			WHERE posts.search_vector @@ q.query
+			AND posts.status = 'published'
+			AND posts.deleted_at IS NULL
+			AND posts.user_id NOT IN (SELECT id FROM users WHERE deletion_due_at IS NOT NULL)`
+		if query.AuthorID != nil {
+			sql += " AND posts.user_id = ?"
+			args = append(args, *query.AuthorID)
+		}
+		if query.Tag != "" {
+			sql += " AND " + taggedWith
+			args = append(args, query.Tag)
+		}
+		sources = append(sources, sql)
+	}
+	if wants(domain.SearchTypeComment) {
+		sql := `SELECT 'comment' AS type, comments.id, posts.title, comments.content AS body,
+			ts_rank(comments.search_vector, q.query) AS rank, comments.post_id, comments.user_id AS author_id, q.query
+			FROM comments JOIN posts ON posts.id = comments.post_id, q
+			WHERE comments.search_vector @@ q.query
+			AND posts.status = 'published'
+			AND posts.deleted_at IS NULL AND comments.deleted_at IS NULL
+			AND comments.user_id NOT IN (SELECT id FROM users WHERE deletion_due_at IS NOT NULL)
+			AND posts.user_id NOT IN (SELECT id FROM users WHERE deletion_due_at IS NOT NULL)`
+		if query.AuthorID != nil {
+			sql += " AND comments.user_id = ?"
+			args = append(args, *query.AuthorID)
+		}
+		if query.Tag != "" {
+			sql += " AND " + taggedWith
+			args = append(args, query.Tag)
+		}
+		sources = append(sources, sql)
+	}
+	// users have no tags, so a tag filter leaves only content
+	if wants(domain.SearchTypeUser) && query.Tag == "" {
+		sql := `SELECT 'user' AS type, users.id, users.username AS title, users.short_bio AS body,
+			ts_rank(users.search_vector, q.query) AS rank, NULL::uuid AS post_id, users.id AS author_id, q.query
+			FROM users, q
+			WHERE users.search_vector @@ q.query
+			AND users.deletion_due_at IS NULL`
+		if query.AuthorID != nil {
+			sql += " AND users.id = ?"
+			args = append(args, *query.AuthorID)
+		}
+		sources = append(sources, sql)
+	}
+	return sources, args
+}
+
+func (r *SearchRepositoryDB) Search(query domain.SearchQuery, page domain.PageRequest) (*domain.Page[domain.SearchResult], error) {
+	results := make([]domain.SearchResult, 0)
+	sources, sourceArgs := searchSources(query)
+	if len(sources) == 0 || len(query.Terms) == 0 {
+		return pageOf(results, page, nil), nil
+	}
+
+	tsqueryExpr, args := tsquery(query.Terms)
+	sql := "WITH q AS (SELECT " + tsqueryExpr + " AS query) " + strings.Join(sources, " UNION ALL ")
+	args = append(args, sourceArgs...)
+
+	key := sortKey{table: "search", column: "rank", cast: "real", desc: true}
+	search := r.db.
+		Table("(?) AS search", r.db.Raw(sql, args...)).
+		Select("search.type, search.id, search.title, search.rank, search.post_id, search.author_id, "+
+			"ts_headline('english', "+escapedBody+", search.query, ?) AS snippet", headlineOptions)
+
+	if err := keysetPage(search, key, page).Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return pageOf(results, page, func(result domain.SearchResult) domain.Cursor {
+		return domain.Cursor{Value: strconv.FormatFloat(float64(result.Rank), 'g', -1, 32), ID: result.ID}
+	}), nil
+}
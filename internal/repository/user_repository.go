@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/ppondeu/go-post-api/internal/domain"
 	"gorm.io/gorm"
@@ -22,17 +24,22 @@ func (r *UserRepositoryDB) Create(user *domain.User) (*domain.User, error) {
 	return user, nil
 }
 
-func (r *UserRepositoryDB) FindAll() ([]domain.User, error) {
+func (r *UserRepositoryDB) FindAll(page domain.PageRequest) (*domain.Page[domain.User], error) {
+	key := sortKey{table: "users", column: "username", cast: "text"}
+	query := r.db.Where("deletion_due_at IS NULL")
+
 	var users []domain.User
-	if err := r.db.Preload("UserSession").Find(&users).Error; err != nil {
+	if err := keysetPage(query, key, page).Find(&users).Error; err != nil {
 		return nil, err
 	}
-	return users, nil
+	return pageOf(users, page, func(user domain.User) domain.Cursor {
+		return domain.Cursor{Value: user.Username, ID: user.ID}
+	}), nil
 }
 
 func (r *UserRepositoryDB) FindByUsername(username string) (*domain.User, error) {
 	var user domain.User
-	if err := r.db.Preload("UserSession").Where("username = ?", username).First(&user).Error; err != nil {
+	if err := r.db.Where("username = ?", username).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -40,7 +47,7 @@ func (r *UserRepositoryDB) FindByUsername(username string) (*domain.User, error)
 
 func (r *UserRepositoryDB) FindByEmail(email string) (*domain.User, error) {
 	var user domain.User
-	if err := r.db.Preload("UserSession").Where("email = ?", email).First(&user).Error; err != nil {
+	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -48,20 +55,29 @@ func (r *UserRepositoryDB) FindByEmail(email string) (*domain.User, error) {
 
 func (r *UserRepositoryDB) FindByID(ID uuid.UUID) (*domain.User, error) {
 	var user domain.User
-	if err := r.db.Preload("UserSession").Where("id = ?", ID).First(&user).Error; err != nil {
+	if err := r.db.Where("id = ?", ID).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
 func (r *UserRepositoryDB) Update(ID uuid.UUID, user *domain.User) (*domain.User, error) {
-	err := r.db.Model(&domain.User{}).Where("id = ?", ID).Updates(user).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// a new email address has to be verified again
+		if user.Email != "" {
+			err := tx.Model(&domain.User{}).Where("id = ? AND email <> ?", ID, user.Email).Update("email_verified", false).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Model(&domain.User{}).Where("id = ?", ID).Updates(user).Error
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	var updatedUser domain.User
-	err = r.db.Preload("UserSession").Where("id = ?", ID).First(&updatedUser).Error
+	err = r.db.Where("id = ?", ID).First(&updatedUser).Error
 	if err != nil {
 		return nil, err
 	}
@@ -77,26 +93,107 @@ func (r *UserRepositoryDB) Delete(ID uuid.UUID) error {
 	return nil
 }
 
-func (r *UserRepositoryDB) UpdateSession(userID uuid.UUID, refreshToken *string) error {
-	err := r.db.Model(&domain.UserSession{}).
-		Preload("UserSession").
-		Where("user_id = ?", userID).
-		Update("refresh_token", refreshToken).Error
+func (r *UserRepositoryDB) SetEmailVerified(ID uuid.UUID, verified bool) error {
+	err := r.db.Model(&domain.User{}).Where("id = ?", ID).Update("email_verified", verified).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *UserRepositoryDB) FindSession(userID uuid.UUID) (*domain.UserSession, error) {
-	var userSession domain.UserSession
-	if err := r.db.Where("user_id = ?", userID).First(&userSession).Error; err != nil {
+func (r *UserRepositoryDB) SetRole(ID uuid.UUID, role domain.Role) error {
+	err := r.db.Model(&domain.User{}).Where("id = ?", ID).Update("role", role).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *UserRepositoryDB) SetPassword(ID uuid.UUID, password string) error {
+	err := r.db.Model(&domain.User{}).Where("id = ?", ID).Update("password", password).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ResetPassword uses up a password reset token and sets the new password
+// together; false means the token was already used.
+func (r *UserRepositoryDB) ResetPassword(tokenID, ID uuid.UUID, password string) (bool, error) {
+	used := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.UserToken{}).
+			Where("id = ? AND used_at IS NULL", tokenID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return nil
+		}
+
+		if err := tx.Model(&domain.User{}).Where("id = ?", ID).Update("password", password).Error; err != nil {
+			return err
+		}
+		used = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return used, nil
+}
+
+func (r *UserRepositoryDB) SetDeletionDueAt(ID uuid.UUID, dueAt *time.Time) error {
+	err := r.db.Model(&domain.User{}).Where("id = ?", ID).Update("deletion_due_at", dueAt).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *UserRepositoryDB) FindDueForDeletion(now time.Time) ([]domain.User, error) {
+	var users []domain.User
+	if err := r.db.Where("deletion_due_at <= ?", now).Find(&users).Error; err != nil {
 		return nil, err
 	}
-	return &userSession, nil
+	return users, nil
+}
+
+func (r *UserRepositoryDB) DeleteIfDue(ID uuid.UUID, now time.Time) (bool, error) {
+	result := r.db.Where("id = ? AND deletion_due_at <= ?", ID, now).Delete(&domain.User{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *UserRepositoryDB) UpdateTwoFactor(ID uuid.UUID, secret *string, enabled bool) error {
+	err := r.db.Model(&domain.User{}).Where("id = ?", ID).Updates(map[string]interface{}{
+		"two_factor_secret":    secret,
+		"two_factor_enabled":   enabled,
+		"two_factor_last_step": 0,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *UserRepositoryDB) SetTwoFactorLastStep(ID uuid.UUID, step int64) error {
+	result := r.db.Model(&domain.User{}).
+		Where("id = ? AND two_factor_last_step < ?", ID, step).
+		Update("two_factor_last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 
-func (r *UserRepositoryDB) CreateUserAndSession(user *domain.User, refreshToken *string) (*domain.User, error) {
+func (r *UserRepositoryDB) CreateUserAndSession(user *domain.User, session *domain.UserSession) (*domain.User, error) {
 	tx := r.db.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -108,11 +205,8 @@ func (r *UserRepositoryDB) CreateUserAndSession(user *domain.User, refreshToken
 		return nil, err
 	}
 
-	userSession := domain.UserSession{
-		UserID:       user.ID,
-		RefreshToken: refreshToken,
-	}
-	if err := tx.Create(&userSession).Error; err != nil {
+	session.UserID = user.ID
+	if err := tx.Create(session).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
@@ -122,13 +216,70 @@ func (r *UserRepositoryDB) CreateUserAndSession(user *domain.User, refreshToken
 		return nil, err
 	}
 
-	user.UserSession = userSession
+	user.UserSessions = []domain.UserSession{*session}
 	return user, nil
 }
 
+func (r *UserRepositoryDB) CreateSession(session *domain.UserSession) (*domain.UserSession, error) {
+	if err := r.db.Create(session).Error; err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (r *UserRepositoryDB) FindSessionByID(ID uuid.UUID) (*domain.UserSession, error) {
+	var userSession domain.UserSession
+	if err := r.db.Where("id = ?", ID).First(&userSession).Error; err != nil {
+		return nil, err
+	}
+	return &userSession, nil
+}
+
+func (r *UserRepositoryDB) FindSessionsByUserID(userID uuid.UUID) ([]domain.UserSession, error) {
+	var userSessions []domain.UserSession
+	if err := r.db.Where("user_id = ?", userID).Order("last_used_at DESC").Find(&userSessions).Error; err != nil {
+		return nil, err
+	}
+	return userSessions, nil
+}
+
+func (r *UserRepositoryDB) UpdateSession(ID uuid.UUID, oldRefreshTokenID, refreshTokenID string) error {
+	result := r.db.Model(&domain.UserSession{}).
+		Where("id = ? AND refresh_token_id = ?", ID, oldRefreshTokenID).
+		Updates(map[string]interface{}{
+			"refresh_token_id": refreshTokenID,
+			"last_used_at":     time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *UserRepositoryDB) DeleteSession(ID uuid.UUID) error {
+	if err := r.db.Where("id = ?", ID).Delete(&domain.UserSession{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *UserRepositoryDB) DeleteSessionsByUserID(userID uuid.UUID, exceptID *uuid.UUID) error {
+	query := r.db.Where("user_id = ?", userID)
+	if exceptID != nil {
+		query = query.Where("id <> ?", *exceptID)
+	}
+	if err := query.Delete(&domain.UserSession{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
 func (r *UserRepositoryDB) FindUserWithRelation(ID uuid.UUID) (*domain.User, error) {
 	var user domain.User
-	if err := r.db.Preload(clause.Associations).Where("id = ?", ID).First(&user).Error; err != nil {
+	if err := r.db.Preload(clause.Associations).Preload("Posts.Tags").Where("id = ? AND deletion_due_at IS NULL", ID).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -136,18 +287,24 @@ func (r *UserRepositoryDB) FindUserWithRelation(ID uuid.UUID) (*domain.User, err
 
 func (r *UserRepositoryDB) FindAllUsersWithRelation() ([]domain.User, error) {
 	var users []domain.User
-	if err := r.db.Preload(clause.Associations).Find(&users).Error; err != nil {
+	if err := r.db.Preload(clause.Associations).Preload("Posts.Tags").Where("deletion_due_at IS NULL").Find(&users).Error; err != nil {
 		return nil, err
 	}
 	return users, nil
 }
 
-func (r *UserRepositoryDB) FindUserBookmarks(userID uuid.UUID) ([]domain.Bookmark, error) {
+func (r *UserRepositoryDB) FindUserBookmarks(userID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.Bookmark], error) {
+	key := sortKey{table: "bookmarks", column: "created_at", cast: "timestamp", desc: true}
+	query := r.db.Preload("Post.User", selectAuthor).Preload("Post.Tags").
+		Where("user_id = ? AND post_id IN (SELECT id FROM posts WHERE status = ? AND deleted_at IS NULL)", userID, domain.PostStatusPublished)
+
 	var bookmarks []domain.Bookmark
-	if err := r.db.Preload("Post").Where("user_id = ?", userID).Find(&bookmarks).Error; err != nil {
+	if err := keysetPage(query, key, page).Find(&bookmarks).Error; err != nil {
 		return nil, err
 	}
-	return bookmarks, nil
+	return pageOf(bookmarks, page, func(bookmark domain.Bookmark) domain.Cursor {
+		return domain.Cursor{Value: cursorTime(bookmark.CreatedAt), ID: bookmark.ID}
+	}), nil
 }
 
 func (r *UserRepositoryDB) AddBookmark(bookmark domain.Bookmark) error {
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+type UserTokenRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepositoryDB(db *gorm.DB) domain.UserTokenRepository {
+	return &UserTokenRepositoryDB{db}
+}
+
+func (r *UserTokenRepositoryDB) Create(token *domain.UserToken) error {
+	if err := r.db.Create(token).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *UserTokenRepositoryDB) FindByHash(purpose, tokenHash string) (*domain.UserToken, error) {
+	var token domain.UserToken
+	if err := r.db.Where("purpose = ? AND token_hash = ?", purpose, tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *UserTokenRepositoryDB) MarkUsed(ID uuid.UUID) (bool, error) {
+	result := r.db.Model(&domain.UserToken{}).
+		Where("id = ? AND used_at IS NULL", ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *UserTokenRepositoryDB) DeleteByUserID(userID uuid.UUID, purpose string) error {
+	if err := r.db.Where("user_id = ? AND purpose = ?", userID, purpose).Delete(&domain.UserToken{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -11,6 +11,13 @@ type ApiResponse struct {
 	StatusCode uint16      `json:"statusCode"`
 	Message    string      `json:"message"`
 	Data       interface{} `json:"data,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+type Pagination struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
 }
 
 func NewApiResponse(c *gin.Context, statusCode int, message string, data interface{}) {
@@ -34,6 +41,15 @@ func NewErrorResponse(c *gin.Context, err error) {
 	}
 }
 
+func NewPaginatedResponse(c *gin.Context, data interface{}, pagination Pagination) {
+	c.JSON(http.StatusOK, ApiResponse{
+		StatusCode: http.StatusOK,
+		Message:    "success",
+		Data:       data,
+		Pagination: &pagination,
+	})
+}
+
 func NewCreatedResponse(c *gin.Context, data interface{}) {
 	NewApiResponse(c, http.StatusCreated, "created", data)
 }
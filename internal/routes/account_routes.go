@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ppondeu/go-post-api/internal/handler"
+	"github.com/ppondeu/go-post-api/internal/middleware"
+	"github.com/ppondeu/go-post-api/internal/usecase"
+)
+
+func SetupAccountRouter(router *gin.Engine, accountHandler *handler.AccountHandler, jwtService *usecase.JwtService, transport middleware.TokenTransport) {
+	session := middleware.ValidateAccessToken(*jwtService, nil, transport)
+	noImpersonation := middleware.BlockImpersonation()
+
+	account := router.Group("api/me")
+	{
+		account.DELETE("", session, noImpersonation, accountHandler.RequestDeletion)
+		account.GET("/export", session, noImpersonation, accountHandler.Export)
+	}
+}
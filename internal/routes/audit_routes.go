@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/handler"
+	"github.com/ppondeu/go-post-api/internal/middleware"
+	"github.com/ppondeu/go-post-api/internal/usecase"
+)
+
+func SetupAuditRouter(router *gin.Engine, auditHandler *handler.AuditHandler, jwtService *usecase.JwtService, transport middleware.TokenTransport) {
+	session := middleware.ValidateAccessToken(*jwtService, nil, transport)
+
+	router.GET("api/me/security-events", session, auditHandler.GetMySecurityEvents)
+	router.GET("api/admin/audit-events", session, middleware.RequirePermission(domain.PermissionViewAuditLog), auditHandler.QueryAuditEvents)
+}
@@ -2,16 +2,43 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/ppondeu/go-post-api/internal/domain"
 	"github.com/ppondeu/go-post-api/internal/handler"
 	"github.com/ppondeu/go-post-api/internal/middleware"
 	"github.com/ppondeu/go-post-api/internal/usecase"
 )
 
-func SetupAuthRouter(router *gin.Engine, authHandler *handler.AuthHandler, jwtService *usecase.JwtService) {
+func SetupAuthRouter(router *gin.Engine, authHandler *handler.AuthHandler, jwtService *usecase.JwtService, transport middleware.TokenTransport) {
+	// account security routes only accept session tokens, never personal access tokens
+	session := middleware.ValidateAccessToken(*jwtService, nil, transport)
+	noImpersonation := middleware.BlockImpersonation()
+
 	auth := router.Group("api/auth")
 	{
+		auth.POST("/register", authHandler.Register)
 		auth.POST("/login", authHandler.Login)
-		auth.POST("/logout", middleware.ValidateRefreshToken(*jwtService), authHandler.Logout)
-		auth.POST("/refresh_token", middleware.ValidateRefreshToken(*jwtService), authHandler.RefreshToken)
+		auth.POST("/logout", middleware.ValidateRefreshToken(*jwtService, transport), authHandler.Logout)
+		auth.POST("/refresh_token", middleware.ValidateRefreshToken(*jwtService, transport), authHandler.RefreshToken)
+
+		auth.GET("/oidc/:provider/login", authHandler.OIDCLogin)
+		auth.GET("/oidc/:provider/callback", authHandler.OIDCCallback)
+
+		auth.POST("/2fa/verify", authHandler.VerifyTwoFactorLogin)
+		auth.POST("/2fa/setup", session, noImpersonation, authHandler.SetupTwoFactor)
+		auth.POST("/2fa/enable", session, noImpersonation, authHandler.EnableTwoFactor)
+		auth.POST("/2fa/disable", session, noImpersonation, authHandler.DisableTwoFactor)
+
+		auth.POST("/verify-email", authHandler.VerifyEmail)
+		auth.POST("/verify-email/resend", session, authHandler.ResendEmailVerification)
+
+		auth.POST("/password/forgot", authHandler.ForgotPassword)
+		auth.POST("/password/reset", authHandler.ResetPassword)
+
+		auth.GET("/sessions", session, authHandler.GetSessions)
+		auth.DELETE("/sessions", session, noImpersonation, authHandler.RevokeOtherSessions)
+		auth.DELETE("/sessions/:id", session, noImpersonation, authHandler.RevokeSession)
+
+		auth.POST("/impersonation", session, noImpersonation, middleware.RequirePermission(domain.PermissionImpersonate), authHandler.StartImpersonation)
+		auth.DELETE("/impersonation", session, authHandler.StopImpersonation)
 	}
 }
@@ -2,14 +2,20 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/ppondeu/go-post-api/internal/domain"
 	"github.com/ppondeu/go-post-api/internal/handler"
+	"github.com/ppondeu/go-post-api/internal/middleware"
+	"github.com/ppondeu/go-post-api/internal/usecase"
 )
 
-func SetupFollowRouter(router *gin.Engine, userHandler *handler.FollowHandler) {
+func SetupFollowRouter(router *gin.Engine, userHandler *handler.FollowHandler, jwtService *usecase.JwtService, patService usecase.PersonalAccessTokenService, transport middleware.TokenTransport) {
+	auth := middleware.ValidateAccessToken(*jwtService, patService, transport)
+	followsWrite := middleware.RequireScope(domain.ScopeFollowsWrite)
+
 	follow := router.Group("api/follow")
 	{
-		follow.POST("/", userHandler.Follow)
-		follow.DELETE("/", userHandler.Unfollow)
+		follow.POST("/", auth, followsWrite, userHandler.Follow)
+		follow.DELETE("/", auth, followsWrite, userHandler.Unfollow)
 		follow.GET("/followers/:id", userHandler.GetFollowers)
 		follow.GET("/followed/:id", userHandler.GetFollowedUsers)
 	}
@@ -0,0 +1,10 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ppondeu/go-post-api/internal/handler"
+)
+
+func SetupJwksRouter(router *gin.Engine, jwksHandler *handler.JwksHandler) {
+	router.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
+}
@@ -2,29 +2,47 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/ppondeu/go-post-api/internal/domain"
 	"github.com/ppondeu/go-post-api/internal/handler"
+	"github.com/ppondeu/go-post-api/internal/middleware"
+	"github.com/ppondeu/go-post-api/internal/usecase"
 )
 
-func SetupPostRouter(router *gin.Engine, postHander *handler.PostHandler) {
+func SetupPostRouter(router *gin.Engine, postHander *handler.PostHandler, jwtService *usecase.JwtService, patService usecase.PersonalAccessTokenService, transport middleware.TokenTransport) {
+	auth := middleware.ValidateAccessToken(*jwtService, patService, transport)
+	optionalAuth := middleware.OptionalAccessToken(*jwtService, patService, transport)
+	postsWrite := middleware.RequireScope(domain.ScopePostsWrite)
+	commentsWrite := middleware.RequireScope(domain.ScopeCommentsWrite)
+
+	router.GET("api/me/posts", auth, postHander.GetMyPosts)
+	router.GET("api/me/trash/posts", auth, postHander.GetTrashedPosts)
+	router.GET("api/me/trash/comments", auth, postHander.GetTrashedComments)
+
 	post := router.Group("api/posts")
 	{
 		post.GET("/", postHander.GetAllPosts)
-		post.GET("/:id", postHander.GetPostByID)
+		post.GET("/:id", optionalAuth, postHander.GetPostByID)
 		post.GET("/user/:id", postHander.GetPostsByUserID)
-		post.POST("/", postHander.CreatePost)
-		post.PATCH("/:id", postHander.UpdatePost)
-		post.DELETE("/:id", postHander.DeletePost)
+		post.POST("/", auth, postsWrite, postHander.CreatePost)
+		post.PATCH("/:id", auth, postsWrite, postHander.UpdatePost)
+		post.DELETE("/:id", auth, postsWrite, postHander.DeletePost)
+		post.POST("/:id/publish", auth, postsWrite, postHander.PublishPost)
+		post.POST("/:id/restore", auth, postsWrite, postHander.RestorePost)
+		post.GET("/:id/revisions", auth, postHander.GetRevisions)
+		post.GET("/:id/revisions/diff", auth, postHander.DiffRevisions)
+		post.POST("/:id/revisions/:rev/restore", auth, postsWrite, postHander.RestoreRevision)
 
 		post.GET("/tags", postHander.GetTags)
-		post.POST("/bookmark", postHander.AddBookmark)
-		post.DELETE("/bookmark", postHander.RemoveBookmark)
-		post.POST("/like", postHander.LikePost)
-		post.DELETE("/like", postHander.UnlikePost)
+		post.POST("/bookmark", auth, postsWrite, postHander.AddBookmark)
+		post.DELETE("/bookmark", auth, postsWrite, postHander.RemoveBookmark)
+		post.POST("/like", auth, postsWrite, postHander.LikePost)
+		post.DELETE("/like", auth, postsWrite, postHander.UnlikePost)
 
-		post.GET("/:id/comments", postHander.GetCommentsByPostID)
-		post.GET("/comment/:id", postHander.GetCommentByID)
-		post.POST("/comment", postHander.AddComment)
-		post.PATCH("/comment/:id", postHander.UpdateComment)
-		post.DELETE("/comment/:id", postHander.DeleteComment)
+		post.GET("/:id/comments", optionalAuth, postHander.GetCommentsByPostID)
+		post.GET("/comment/:id", optionalAuth, postHander.GetCommentByID)
+		post.POST("/comment", auth, commentsWrite, postHander.AddComment)
+		post.PATCH("/comment/:id", auth, commentsWrite, postHander.UpdateComment)
+		post.DELETE("/comment/:id", auth, commentsWrite, postHander.DeleteComment)
+		post.POST("/comment/:id/restore", auth, commentsWrite, postHander.RestoreComment)
 	}
 }
@@ -0,0 +1,10 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ppondeu/go-post-api/internal/handler"
+)
+
+func SetupSearchRouter(router *gin.Engine, searchHandler *handler.SearchHandler) {
+	router.GET("api/search", searchHandler.Search)
+}
@@ -0,0 +1,10 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ppondeu/go-post-api/internal/handler"
+)
+
+func SetupTagRouter(router *gin.Engine, postHandler *handler.PostHandler) {
+	router.GET("api/tags/:slug/posts", postHandler.GetPostsByTag)
+}
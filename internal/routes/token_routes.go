@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/ppondeu/go-post-api/internal/handler"
+	"github.com/ppondeu/go-post-api/internal/middleware"
+	"github.com/ppondeu/go-post-api/internal/usecase"
+)
+
+func SetupTokenRouter(router *gin.Engine, tokenHandler *handler.TokenHandler, jwtService *usecase.JwtService, transport middleware.TokenTransport) {
+	session := middleware.ValidateAccessToken(*jwtService, nil, transport)
+	noImpersonation := middleware.BlockImpersonation()
+
+	token := router.Group("api/auth/tokens")
+	{
+		token.GET("/", session, tokenHandler.GetTokens)
+		token.POST("/", session, noImpersonation, tokenHandler.CreateToken)
+		token.DELETE("/:id", session, noImpersonation, tokenHandler.RevokeToken)
+	}
+}
@@ -2,10 +2,17 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/ppondeu/go-post-api/internal/domain"
 	"github.com/ppondeu/go-post-api/internal/handler"
+	"github.com/ppondeu/go-post-api/internal/middleware"
+	"github.com/ppondeu/go-post-api/internal/usecase"
 )
 
-func SetupUserRouter(router *gin.Engine, userHandler *handler.UserHandler) {
+func SetupUserRouter(router *gin.Engine, userHandler *handler.UserHandler, jwtService *usecase.JwtService, patService usecase.PersonalAccessTokenService, transport middleware.TokenTransport) {
+	auth := middleware.ValidateAccessToken(*jwtService, patService, transport)
+	profileWrite := middleware.RequireScope(domain.ScopeProfileWrite)
+	noImpersonation := middleware.BlockImpersonation()
+
 	user := router.Group("api/users")
 	{
 		user.GET("/", userHandler.GetAllUsers)
@@ -15,9 +22,9 @@ func SetupUserRouter(router *gin.Engine, userHandler *handler.UserHandler) {
 		user.GET("/:id", userHandler.GetUserByID)
 
 		user.POST("/", userHandler.CreateUser)
-		user.PATCH("/:id", userHandler.UpdateUser)
-		user.DELETE("/:id", userHandler.DeleteUser)
-		user.PATCH("/session/:id", userHandler.UpdateUserSession)
+		user.PATCH("/:id", auth, profileWrite, noImpersonation, userHandler.UpdateUser)
+		user.DELETE("/:id", auth, profileWrite, noImpersonation, userHandler.DeleteUser)
+		user.PATCH("/:id/role", auth, noImpersonation, middleware.RequirePermission(domain.PermissionManageRoles), userHandler.UpdateUserRole)
 
 		user.GET("/test", userHandler.GetUsersWithRelation)
 		user.GET("/test/:id", userHandler.GetUserWithRelation)
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/dto"
+	"github.com/ppondeu/go-post-api/internal/logger"
+)
+
+type AuditService interface {
+	Record(event domain.AuditEvent)
+	GetUserEvents(userID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.AuditEvent], error)
+	QueryEvents(filter domain.AuditEventFilter, page domain.PageRequest) (*domain.Page[domain.AuditEvent], error)
+}
+
+type auditServiceImpl struct {
+	auditEventRepo domain.AuditEventRepository
+}
+
+func NewAuditService(auditEventRepo domain.AuditEventRepository) AuditService {
+	return &auditServiceImpl{
+		auditEventRepo: auditEventRepo,
+	}
+}
+
+// newAuditEvent describes an action a user performed on their own account;
+// set ActorID separately when someone else acted on it.
+func newAuditEvent(eventType, outcome, userID string, clientInfo dto.ClientInfoDto) domain.AuditEvent {
+	event := domain.AuditEvent{
+		Type:      eventType,
+		Outcome:   outcome,
+		IP:        clientInfo.IP,
+		UserAgent: clientInfo.UserAgent,
+	}
+	if userID != "" {
+		event.UserID = &userID
+		event.ActorID = &userID
+	}
+	return event
+}
+
+// Record never fails the request it is called from; a lost audit row is
+// logged instead.
+func (s *auditServiceImpl) Record(event domain.AuditEvent) {
+	if len(event.UserAgent) > 512 {
+		event.UserAgent = event.UserAgent[:512]
+	}
+	if len(event.Details) > 255 {
+		event.Details = event.Details[:255]
+	}
+	if err := s.auditEventRepo.Create(&event); err != nil {
+		logger.Error(err)
+	}
+}
+
+func (s *auditServiceImpl) GetUserEvents(userID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.AuditEvent], error) {
+	return s.QueryEvents(domain.AuditEventFilter{UserID: &userID}, page)
+}
+
+func (s *auditServiceImpl) QueryEvents(filter domain.AuditEventFilter, page domain.PageRequest) (*domain.Page[domain.AuditEvent], error) {
+	events, err := s.auditEventRepo.Find(filter, page)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return events, nil
+}
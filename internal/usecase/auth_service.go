@@ -1,62 +1,110 @@
 package usecase
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
 	"github.com/ppondeu/go-post-api/internal/dto"
 	"github.com/ppondeu/go-post-api/internal/errors"
 	"github.com/ppondeu/go-post-api/internal/logger"
+	"github.com/ppondeu/go-post-api/internal/mailer"
 	"github.com/ppondeu/go-post-api/internal/utils"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type AuthService interface {
-	Login(authRequestDto dto.AuthRequestDTO) (*dto.TokenResponseDto, error)
-	RefreshToken(refreshToken string, ID uuid.UUID) (*dto.TokenResponseDto, error)
-	Logout(refreshToken string, ID uuid.UUID) error
+	Register(createUserDto dto.CreateUserDto, clientInfo dto.ClientInfoDto) (*dto.TokenResponseDto, error)
+	Login(authRequestDto dto.AuthRequestDTO, clientInfo dto.ClientInfoDto) (*dto.TokenResponseDto, error)
+	RefreshToken(refreshToken string, ID uuid.UUID, clientInfo dto.ClientInfoDto) (*dto.TokenResponseDto, error)
+	Logout(refreshToken string, ID uuid.UUID, clientInfo dto.ClientInfoDto) error
+
+	OIDCAuthorizationURL(provider string) (authURL, state string, err error)
+	OIDCLogin(provider, code, state string, clientInfo dto.ClientInfoDto) (*dto.TokenResponseDto, error)
+
+	VerifyTwoFactorLogin(twoFactorLoginDto dto.TwoFactorLoginDto, clientInfo dto.ClientInfoDto) (*dto.TokenResponseDto, error)
+	SetupTwoFactor(userID uuid.UUID) (*dto.TwoFactorSetupResponseDto, error)
+	EnableTwoFactor(userID uuid.UUID, code string, clientInfo dto.ClientInfoDto) (*dto.RecoveryCodesResponseDto, error)
+	DisableTwoFactor(userID uuid.UUID, password string, clientInfo dto.ClientInfoDto) error
+
+	VerifyEmail(token string, clientInfo dto.ClientInfoDto) error
+	ResendEmailVerification(userID uuid.UUID) error
+
+	ForgotPassword(email string, clientInfo dto.ClientInfoDto) error
+	ResetPassword(token, password string, clientInfo dto.ClientInfoDto) error
+
+	GetSessions(userID, currentSessionID uuid.UUID) ([]dto.SessionResponseDto, error)
+	RevokeSession(userID, sessionID uuid.UUID, clientInfo dto.ClientInfoDto) error
+	RevokeOtherSessions(userID, currentSessionID uuid.UUID, clientInfo dto.ClientInfoDto) error
+
+	StartImpersonation(actorID, userID uuid.UUID, reason string, clientInfo dto.ClientInfoDto) (*dto.TokenResponseDto, error)
+	StopImpersonation(claims *UserClaims, clientInfo dto.ClientInfoDto) error
+}
+
+type AuthConfig struct {
+	AppBaseURL       string
+	PasswordResetTTL time.Duration
+	ImpersonationTTL time.Duration
+	RefreshTokenTTL  time.Duration
+	TOTPIssuer       string
 }
 
 type authServiceImpl struct {
-	userService UserService
-	jwtService  JwtService
+	userService      UserService
+	jwtService       JwtService
+	auditService     AuditService
+	userTokenRepo    domain.UserTokenRepository
+	recoveryCodeRepo domain.RecoveryCodeRepository
+	loginThrottle    LoginThrottleService
+	oidcService      OIDCService
+	mailer           mailer.Mailer
+	config           AuthConfig
 }
 
 type UserClaims struct {
 	jwt.RegisteredClaims
-	Sub       string `json:"sub"`
-	Username  string `json:"username"`
-	TokenType string `json:"tokenType"`
+	Sub       string   `json:"sub"`
+	Sid       string   `json:"sid"`
+	Username  string   `json:"username"`
+	TokenType string   `json:"tokenType"`
+	Role      string   `json:"role,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+
+	ImpersonatorID string `json:"impersonatorID,omitempty"`
 }
 
-func NewAuthService(userService UserService, jwtService JwtService) AuthService {
+func NewAuthService(userService UserService, jwtService JwtService, auditService AuditService, userTokenRepo domain.UserTokenRepository, recoveryCodeRepo domain.RecoveryCodeRepository, loginThrottle LoginThrottleService, oidcService OIDCService, mailer mailer.Mailer, config AuthConfig) AuthService {
 	return &authServiceImpl{
-		userService: userService,
-		jwtService:  jwtService,
+		userService:      userService,
+		jwtService:       jwtService,
+		auditService:     auditService,
+		userTokenRepo:    userTokenRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		loginThrottle:    loginThrottle,
+		oidcService:      oidcService,
+		mailer:           mailer,
+		config:           config,
 	}
 }
 
-func (s *authServiceImpl) Login(authRequestDto dto.AuthRequestDTO) (*dto.TokenResponseDto, error) {
-	user, err := s.userService.GetUserByEmail(authRequestDto.Email)
-	if err != nil {
-		logger.Error(err)
-		return nil, err
-	}
-
-	err = utils.CompareHashAndPassword(user.Password, authRequestDto.Password)
-	if err != nil {
-		logger.Error(err)
-		return nil, err
-	}
-
+func (s *authServiceImpl) generateTokens(user *domain.User, sessionID, refreshTokenID string) (*dto.TokenResponseDto, error) {
+	expiresAt := time.Now().Add(time.Minute * 15)
 	userClaims := UserClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 15)),
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 		Sub:       user.ID,
+		Sid:       sessionID,
 		Username:  user.Username,
 		TokenType: "access",
+		Role:      string(user.Role),
 	}
 
 	access, err := s.jwtService.GenerateToken(userClaims, "access")
@@ -65,7 +113,10 @@ func (s *authServiceImpl) Login(authRequestDto dto.AuthRequestDTO) (*dto.TokenRe
 		return nil, err
 	}
 
+	refreshExpiresAt := time.Now().Add(s.config.RefreshTokenTTL)
+	userClaims.ID = refreshTokenID
 	userClaims.TokenType = "refresh"
+	userClaims.ExpiresAt = jwt.NewNumericDate(refreshExpiresAt)
 
 	refresh, err := s.jwtService.GenerateToken(userClaims, "refresh")
 	if err != nil {
@@ -73,26 +124,161 @@ func (s *authServiceImpl) Login(authRequestDto dto.AuthRequestDTO) (*dto.TokenRe
 		return nil, err
 	}
 
-	userID, err := uuid.Parse(user.ID)
+	return &dto.TokenResponseDto{
+		AccessToken:           *access,
+		AccessTokenExpiresAt:  &expiresAt,
+		RefreshToken:          *refresh,
+		RefreshTokenExpiresAt: &refreshExpiresAt,
+	}, nil
+}
+
+// A session is a refresh token family; replaying a rotated token revokes it.
+func (s *authServiceImpl) revokeTokenFamily(session *domain.UserSession, clientInfo dto.ClientInfoDto) {
+	sessionID := uuid.MustParse(session.ID)
+	if err := s.userService.DeleteUserSession(sessionID); err != nil {
+		logger.Error(err)
+	}
+
+	event := newAuditEvent(domain.AuditEventRefreshTokenReuse, domain.AuditOutcomeFailure, session.UserID, clientInfo)
+	event.ActorID = nil
+	event.SessionID = &session.ID
+	s.auditService.Record(event)
+}
+
+func (s *authServiceImpl) Register(createUserDto dto.CreateUserDto, clientInfo dto.ClientInfoDto) (*dto.TokenResponseDto, error) {
+	refreshTokenID := uuid.New().String()
+	session := &domain.UserSession{
+		ID:             uuid.New().String(),
+		RefreshTokenID: &refreshTokenID,
+		UserAgent:      clientInfo.UserAgent,
+		IP:             clientInfo.IP,
+		LastUsedAt:     time.Now(),
+	}
+
+	user, err := s.userService.CreateUserAndSession(&createUserDto, session)
 	if err != nil {
 		logger.Error(err)
 		return nil, err
 	}
 
-	err = s.userService.UpdateUserSession(userID, refresh)
+	event := newAuditEvent(domain.AuditEventAccountCreated, domain.AuditOutcomeSuccess, user.ID, clientInfo)
+	event.SessionID = &session.ID
+	s.auditService.Record(event)
+
+	authResponse, err := s.generateTokens(user, session.ID, refreshTokenID)
 	if err != nil {
-		logger.Error(err)
 		return nil, err
 	}
 
-	authResponse := &dto.TokenResponseDto{
-		AccessToken:  *access,
-		RefreshToken: *refresh,
+	return authResponse, nil
+}
+
+func (s *authServiceImpl) Login(authRequestDto dto.AuthRequestDTO, clientInfo dto.ClientInfoDto) (*dto.TokenResponseDto, error) {
+	err := s.loginThrottle.Check(authRequestDto.Email, clientInfo.IP)
+	if err != nil {
+		event := newAuditEvent(domain.AuditEventLogin, domain.AuditOutcomeFailure, "", clientInfo)
+		event.Details = "throttled: " + authRequestDto.Email
+		s.auditService.Record(event)
+		return nil, err
+	}
+
+	user, err := s.userService.GetUserByEmail(authRequestDto.Email)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != http.StatusNotFound {
+			return nil, err
+		}
+		s.userService.VerifyPassword(nil, authRequestDto.Password)
+		return nil, s.loginFailed(nil, authRequestDto.Email, clientInfo)
+	}
+
+	if !s.userService.VerifyPassword(user, authRequestDto.Password) {
+		return nil, s.loginFailed(user, authRequestDto.Email, clientInfo)
+	}
+
+	if user.TwoFactorEnabled {
+		return s.generateMfaToken(user)
 	}
+
+	err = s.loginThrottle.Reset(authRequestDto.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createSession(user, "password", clientInfo)
+}
+
+func (s *authServiceImpl) OIDCAuthorizationURL(provider string) (string, string, error) {
+	return s.oidcService.AuthorizationURL(provider)
+}
+
+func (s *authServiceImpl) OIDCLogin(provider, code, state string, clientInfo dto.ClientInfoDto) (*dto.TokenResponseDto, error) {
+	user, err := s.oidcService.Authenticate(provider, code, state)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled {
+		return s.generateMfaToken(user)
+	}
+
+	return s.createSession(user, "oidc:"+provider, clientInfo)
+}
+
+// loginFailed records a failed password check; user is nil when no account
+// matches the email.
+func (s *authServiceImpl) loginFailed(user *domain.User, email string, clientInfo dto.ClientInfoDto) error {
+	logger.Info("login failed", zap.String("ip", clientInfo.IP))
+	event := newAuditEvent(domain.AuditEventLogin, domain.AuditOutcomeFailure, "", clientInfo)
+	if user != nil {
+		event.UserID = &user.ID
+		event.Details = "invalid password"
+	} else {
+		event.Details = "unknown account: " + email
+	}
+	s.auditService.Record(event)
+
+	if err := s.loginThrottle.RecordFailure(email, clientInfo.IP); err != nil {
+		return err
+	}
+	return errors.NewUnauthorizedError("invalid credentials")
+}
+
+func (s *authServiceImpl) createSession(user *domain.User, method string, clientInfo dto.ClientInfoDto) (*dto.TokenResponseDto, error) {
+	err := s.userService.CancelDeletion(user, clientInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID := uuid.New().String()
+	refreshTokenID := uuid.New().String()
+	authResponse, err := s.generateTokens(user, sessionID, refreshTokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &domain.UserSession{
+		ID:             sessionID,
+		UserID:         user.ID,
+		RefreshTokenID: &refreshTokenID,
+		UserAgent:      clientInfo.UserAgent,
+		IP:             clientInfo.IP,
+		LastUsedAt:     time.Now(),
+	}
+	_, err = s.userService.CreateUserSession(session)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	event := newAuditEvent(domain.AuditEventLogin, domain.AuditOutcomeSuccess, user.ID, clientInfo)
+	event.SessionID = &session.ID
+	event.Details = method
+	s.auditService.Record(event)
+
 	return authResponse, nil
 }
 
-func (s *authServiceImpl) RefreshToken(refreshToken string, ID uuid.UUID) (*dto.TokenResponseDto, error) {
+func (s *authServiceImpl) RefreshToken(refreshToken string, ID uuid.UUID, clientInfo dto.ClientInfoDto) (*dto.TokenResponseDto, error) {
 	claims, err := s.jwtService.ValidateToken(refreshToken, "refresh")
 	if err != nil {
 		logger.Error(err)
@@ -109,83 +295,527 @@ func (s *authServiceImpl) RefreshToken(refreshToken string, ID uuid.UUID) (*dto.
 		return nil, err
 	}
 
+	sessionID, err := uuid.Parse(claims.Sid)
+	if err != nil {
+		logger.Error(err)
+		return nil, errors.NewForbiddenError("invalid refresh token")
+	}
+
 	user, err := s.userService.GetUserByID(userID)
 	if err != nil {
 		logger.Error(err)
 		return nil, err
 	}
 
-	if user.UserSession.RefreshToken == nil || claims.Username != user.Username {
+	session, err := s.userService.GetUserSession(sessionID)
+	if err != nil {
 		logger.Error(err)
 		return nil, errors.NewForbiddenError("invalid refresh token")
 	}
 
-	if *user.UserSession.RefreshToken != refreshToken {
-		logger.Error(err)
+	if session.UserID != user.ID || session.RefreshTokenID == nil || claims.Username != user.Username {
+		logger.Error("refresh token does not match session")
+		return nil, errors.NewForbiddenError("invalid refresh token")
+	}
+
+	if *session.RefreshTokenID != claims.ID {
+		logger.Error("refresh token reuse detected", zap.String("sessionID", session.ID))
+		s.revokeTokenFamily(session, clientInfo)
+		return nil, errors.NewForbiddenError("invalid refresh token")
+	}
+
+	refreshTokenID := uuid.New().String()
+	authResponse, err := s.generateTokens(user, session.ID, refreshTokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	// a concurrent refresh with the same token rotated the session first
+	err = s.userService.UpdateUserSession(sessionID, claims.ID, refreshTokenID)
+	if err == gorm.ErrRecordNotFound {
+		logger.Error("refresh token reuse detected", zap.String("sessionID", session.ID))
+		s.revokeTokenFamily(session, clientInfo)
 		return nil, errors.NewForbiddenError("invalid refresh token")
 	}
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	event := newAuditEvent(domain.AuditEventTokenRefreshed, domain.AuditOutcomeSuccess, user.ID, clientInfo)
+	event.SessionID = &session.ID
+	s.auditService.Record(event)
+
+	return authResponse, nil
+}
+
+func (s *authServiceImpl) Logout(refreshToken string, ID uuid.UUID, clientInfo dto.ClientInfoDto) error {
+	claims, err := s.jwtService.ValidateToken(refreshToken, "refresh")
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	sessionID, err := uuid.Parse(claims.Sid)
+	if err != nil {
+		logger.Error(err)
+		return errors.NewForbiddenError("invalid refresh token")
+	}
+
+	session, err := s.userService.GetUserSession(sessionID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if session.UserID != ID.String() || session.RefreshTokenID == nil {
+		logger.Error("refresh token does not match session")
+		return errors.NewForbiddenError("invalid refresh token")
+	}
+
+	if *session.RefreshTokenID != claims.ID {
+		logger.Error("refresh token does not match session")
+		return errors.NewForbiddenError("invalid refresh token")
+	}
 
+	err = s.userService.DeleteUserSession(sessionID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	event := newAuditEvent(domain.AuditEventLogout, domain.AuditOutcomeSuccess, session.UserID, clientInfo)
+	event.SessionID = &session.ID
+	s.auditService.Record(event)
+
+	return nil
+}
+
+func (s *authServiceImpl) generateMfaToken(user *domain.User) (*dto.TokenResponseDto, error) {
 	userClaims := UserClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 15)),
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 5)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 		Sub:       user.ID,
 		Username:  user.Username,
-		TokenType: "access",
+		TokenType: "mfa",
 	}
 
-	access, err := s.jwtService.GenerateToken(userClaims, "access")
+	mfaToken, err := s.jwtService.GenerateToken(userClaims, "mfa")
 	if err != nil {
 		logger.Error(err)
 		return nil, err
 	}
 
-	userClaims.TokenType = "refresh"
+	return &dto.TokenResponseDto{
+		MfaRequired: true,
+		MfaToken:    *mfaToken,
+	}, nil
+}
 
-	refresh, err := s.jwtService.GenerateToken(userClaims, "refresh")
+func (s *authServiceImpl) verifySecondFactor(user *domain.User, code string) error {
+	userID := uuid.MustParse(user.ID)
+	code = strings.TrimSpace(code)
+
+	if user.TwoFactorSecret != nil {
+		step, ok := utils.ValidateTOTP(*user.TwoFactorSecret, code, time.Now(), 1)
+		if ok {
+			return s.userService.SetTwoFactorLastStep(userID, step)
+		}
+	}
+
+	used, err := s.recoveryCodeRepo.Use(userID, utils.HashToken(strings.ToLower(code)))
 	if err != nil {
 		logger.Error(err)
+		return err
+	}
+	if !used {
+		return errors.NewUnauthorizedError("invalid two-factor code")
+	}
+	return nil
+}
+
+func (s *authServiceImpl) generateRecoveryCodes(userID uuid.UUID) ([]string, error) {
+	codes := make([]string, 0, 10)
+	codeHashes := make([]string, 0, 10)
+	for i := 0; i < 10; i++ {
+		token, err := utils.GenerateRandomToken()
+		if err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(token[:5] + "-" + token[5:10])
+		codes = append(codes, code)
+		codeHashes = append(codeHashes, utils.HashToken(code))
+	}
+
+	err := s.recoveryCodeRepo.Replace(userID, codeHashes)
+	if err != nil {
 		return nil, err
 	}
+	return codes, nil
+}
 
-	err = s.userService.UpdateUserSession(userID, refresh)
+func (s *authServiceImpl) VerifyTwoFactorLogin(twoFactorLoginDto dto.TwoFactorLoginDto, clientInfo dto.ClientInfoDto) (*dto.TokenResponseDto, error) {
+	claims, err := s.jwtService.ValidateToken(twoFactorLoginDto.MfaToken, "mfa")
+	if err != nil {
+		logger.Error(err)
+		return nil, errors.NewUnauthorizedError("invalid mfa token")
+	}
+
+	userID, err := uuid.Parse(claims.Sub)
 	if err != nil {
 		logger.Error(err)
+		return nil, errors.NewUnauthorizedError("invalid mfa token")
+	}
+
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
 		return nil, err
 	}
 
-	authResponse := &dto.TokenResponseDto{
-		AccessToken:  *access,
-		RefreshToken: *refresh,
+	if !user.TwoFactorEnabled {
+		return nil, errors.NewBadRequestError("two-factor authentication is not enabled")
 	}
-	return authResponse, nil
+
+	err = s.loginThrottle.Check(user.Email, clientInfo.IP)
+	if err != nil {
+		event := newAuditEvent(domain.AuditEventLogin, domain.AuditOutcomeFailure, user.ID, clientInfo)
+		event.Details = "throttled"
+		s.auditService.Record(event)
+		return nil, err
+	}
+
+	err = s.verifySecondFactor(user, twoFactorLoginDto.Code)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Code == http.StatusUnauthorized {
+			event := newAuditEvent(domain.AuditEventLogin, domain.AuditOutcomeFailure, user.ID, clientInfo)
+			event.Details = "invalid two-factor code"
+			s.auditService.Record(event)
+			if err := s.loginThrottle.RecordFailure(user.Email, clientInfo.IP); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	err = s.loginThrottle.Reset(user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createSession(user, "two_factor", clientInfo)
 }
 
-func (s *authServiceImpl) Logout(refreshToken string, ID uuid.UUID) error {
+func (s *authServiceImpl) SetupTwoFactor(userID uuid.UUID) (*dto.TwoFactorSetupResponseDto, error) {
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, errors.NewBadRequestError("two-factor authentication is already enabled")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		logger.Error(err)
+		return nil, errors.NewInternalServerError()
+	}
+
+	err = s.userService.UpdateTwoFactor(userID, &secret, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TwoFactorSetupResponseDto{
+		Secret:     secret,
+		OtpauthURI: utils.TOTPURI(s.config.TOTPIssuer, user.Email, secret),
+	}, nil
+}
+
+func (s *authServiceImpl) EnableTwoFactor(userID uuid.UUID, code string, clientInfo dto.ClientInfoDto) (*dto.RecoveryCodesResponseDto, error) {
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, errors.NewBadRequestError("two-factor authentication is already enabled")
+	}
+
+	if user.TwoFactorSecret == nil {
+		return nil, errors.NewBadRequestError("two-factor setup has not been started")
+	}
+
+	step, ok := utils.ValidateTOTP(*user.TwoFactorSecret, strings.TrimSpace(code), time.Now(), 1)
+	if !ok {
+		return nil, errors.NewBadRequestError("invalid two-factor code")
+	}
+
+	err = s.userService.UpdateTwoFactor(userID, user.TwoFactorSecret, true)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.userService.SetTwoFactorLastStep(userID, step)
+	if err != nil {
+		return nil, err
+	}
 
-	user, err := s.userService.GetUserSession(ID)
+	codes, err := s.generateRecoveryCodes(userID)
 	if err != nil {
 		logger.Error(err)
+		return nil, err
+	}
+
+	s.auditService.Record(newAuditEvent(domain.AuditEventTwoFactorEnabled, domain.AuditOutcomeSuccess, user.ID, clientInfo))
+
+	return &dto.RecoveryCodesResponseDto{RecoveryCodes: codes}, nil
+}
+
+func (s *authServiceImpl) DisableTwoFactor(userID uuid.UUID, password string, clientInfo dto.ClientInfoDto) error {
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
 		return err
 	}
 
-	if user.RefreshToken == nil {
+	if !user.TwoFactorEnabled {
+		return errors.NewBadRequestError("two-factor authentication is not enabled")
+	}
+
+	if !s.userService.VerifyPassword(user, password) {
+		logger.Error("invalid password")
+		event := newAuditEvent(domain.AuditEventTwoFactorDisabled, domain.AuditOutcomeFailure, user.ID, clientInfo)
+		event.Details = "invalid password"
+		s.auditService.Record(event)
+		return errors.NewUnauthorizedError("invalid password")
+	}
+
+	err = s.userService.UpdateTwoFactor(userID, nil, false)
+	if err != nil {
+		return err
+	}
+
+	err = s.recoveryCodeRepo.DeleteByUserID(userID)
+	if err != nil {
 		logger.Error(err)
-		return errors.NewForbiddenError("invalid refresh token")
+		return err
+	}
+
+	s.auditService.Record(newAuditEvent(domain.AuditEventTwoFactorDisabled, domain.AuditOutcomeSuccess, user.ID, clientInfo))
+	return nil
+}
+
+func (s *authServiceImpl) VerifyEmail(token string, clientInfo dto.ClientInfoDto) error {
+	return s.userService.VerifyEmail(token, clientInfo)
+}
+
+func (s *authServiceImpl) ResendEmailVerification(userID uuid.UUID) error {
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return err
 	}
 
-	if *user.RefreshToken != refreshToken {
+	return s.userService.SendEmailVerification(user)
+}
+
+func (s *authServiceImpl) ForgotPassword(email string, clientInfo dto.ClientInfoDto) error {
+	user, err := s.userService.GetUserByEmail(email)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Code == http.StatusNotFound {
+			return nil
+		}
 		logger.Error(err)
-		return errors.NewForbiddenError("invalid refresh token")
+		return err
 	}
 
-	err = s.userService.UpdateUserSession(ID, nil)
+	userID := uuid.MustParse(user.ID)
+	err = s.userTokenRepo.DeleteByUserID(userID, domain.UserTokenPasswordReset)
 	if err != nil {
 		logger.Error(err)
 		return err
 	}
 
+	token, err := utils.GenerateRandomToken()
+	if err != nil {
+		logger.Error(err)
+		return errors.NewInternalServerError()
+	}
+
+	userToken := &domain.UserToken{
+		UserID:    user.ID,
+		Purpose:   domain.UserTokenPasswordReset,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.config.PasswordResetTTL),
+	}
+	err = s.userTokenRepo.Create(userToken)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	message := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Use the link below to reset your password. It expires in %v.\n\n%s/reset-password?token=%s\n",
+			s.config.PasswordResetTTL, s.config.AppBaseURL, token),
+	}
+	err = s.mailer.Send(message)
+	if err != nil {
+		logger.Error(err)
+		return errors.NewInternalServerError()
+	}
+
+	event := newAuditEvent(domain.AuditEventPasswordResetRequested, domain.AuditOutcomeSuccess, user.ID, clientInfo)
+	event.ActorID = nil
+	s.auditService.Record(event)
+	return nil
+}
+
+func (s *authServiceImpl) ResetPassword(token, password string, clientInfo dto.ClientInfoDto) error {
+	userToken, err := s.userTokenRepo.FindByHash(domain.UserTokenPasswordReset, utils.HashToken(token))
+	if err != nil {
+		logger.Error(err)
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewBadRequestError("invalid or expired reset token")
+		}
+		return err
+	}
+
+	if userToken.UsedAt != nil || time.Now().After(userToken.ExpiresAt) {
+		return errors.NewBadRequestError("invalid or expired reset token")
+	}
+
+	userID := uuid.MustParse(userToken.UserID)
+	err = s.userService.ResetPassword(uuid.MustParse(userToken.ID), userID, password, clientInfo)
+	if err != nil {
+		return err
+	}
+
+	err = s.userService.DeleteUserSessions(userID, nil)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *authServiceImpl) GetSessions(userID, currentSessionID uuid.UUID) ([]dto.SessionResponseDto, error) {
+	sessions, err := s.userService.GetUserSessions(userID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	sessionResponseDtos := make([]dto.SessionResponseDto, 0, len(sessions))
+	for _, session := range sessions {
+		sessionResponseDtos = append(sessionResponseDtos, dto.SessionResponseDto{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			Current:    session.ID == currentSessionID.String(),
+		})
+	}
+	return sessionResponseDtos, nil
+}
+
+func (s *authServiceImpl) RevokeSession(userID, sessionID uuid.UUID, clientInfo dto.ClientInfoDto) error {
+	session, err := s.userService.GetUserSession(sessionID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if session.UserID != userID.String() {
+		logger.Error("You can't revoke another user's session")
+		return errors.NewNotFoundError("User session not found")
+	}
+
+	err = s.userService.DeleteUserSession(sessionID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	event := newAuditEvent(domain.AuditEventSessionRevoked, domain.AuditOutcomeSuccess, session.UserID, clientInfo)
+	event.SessionID = &session.ID
+	s.auditService.Record(event)
 	return nil
+}
+
+func (s *authServiceImpl) RevokeOtherSessions(userID, currentSessionID uuid.UUID, clientInfo dto.ClientInfoDto) error {
+	err := s.userService.DeleteUserSessions(userID, &currentSessionID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	event := newAuditEvent(domain.AuditEventSessionRevoked, domain.AuditOutcomeSuccess, userID.String(), clientInfo)
+	event.Details = "all other sessions"
+	s.auditService.Record(event)
+	return nil
+}
+
+func (s *authServiceImpl) StartImpersonation(actorID, userID uuid.UUID, reason string, clientInfo dto.ClientInfoDto) (*dto.TokenResponseDto, error) {
+	actor, err := s.userService.GetUserByID(actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := newAuditEvent(domain.AuditEventImpersonationStarted, domain.AuditOutcomeSuccess, userID.String(), clientInfo)
+	event.ActorID = &actor.ID
+	event.Details = reason
 
+	if !actor.Role.HasPermission(domain.PermissionImpersonate) {
+		logger.Error("You can't impersonate users")
+		event.Outcome = domain.AuditOutcomeFailure
+		s.auditService.Record(event)
+		return nil, errors.NewForbiddenError("You can't impersonate users")
+	}
+
+	if actorID == userID {
+		return nil, errors.NewBadRequestError("You can't impersonate yourself")
+	}
+
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role.HasPermission(domain.PermissionImpersonate) {
+		logger.Error("You can't impersonate another admin")
+		event.Outcome = domain.AuditOutcomeFailure
+		s.auditService.Record(event)
+		return nil, errors.NewForbiddenError("You can't impersonate another admin")
+	}
+
+	accessToken, expiresAt, err := s.jwtService.GenerateImpersonationToken(user, actor.ID, s.config.ImpersonationTTL)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	s.auditService.Record(event)
+	return &dto.TokenResponseDto{
+		AccessToken:          *accessToken,
+		AccessTokenExpiresAt: expiresAt,
+	}, nil
+}
+
+// StopImpersonation only records the end; the token stays valid until it
+// expires, so clients must discard it.
+func (s *authServiceImpl) StopImpersonation(claims *UserClaims, clientInfo dto.ClientInfoDto) error {
+	if claims.ImpersonatorID == "" {
+		return errors.NewBadRequestError("not impersonating")
+	}
+
+	event := newAuditEvent(domain.AuditEventImpersonationStopped, domain.AuditOutcomeSuccess, claims.Sub, clientInfo)
+	event.ActorID = &claims.ImpersonatorID
+	s.auditService.Record(event)
+	return nil
 }
@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/dto"
+	"github.com/ppondeu/go-post-api/internal/mailer"
+)
+
+var resetLinkPattern = regexp.MustCompile(`http://app\.test/reset-password\?token=(\S+)`)
+
+type passwordResetFixture struct {
+	auth     AuthService
+	users    UserService
+	userRepo *fakeUserRepo
+	mail     *mailer.MemoryMailer
+	user     *domain.User
+}
+
+func newPasswordResetFixture(t *testing.T) *passwordResetFixture {
+	t.Helper()
+	mail := mailer.NewMemoryMailer()
+	userRepo := newFakeUserRepo(newFakeUserTokenRepo())
+	users := testUserService(userRepo, mail)
+	auth := NewAuthService(users, NewJwtService([]byte("access"), []byte("refresh")), &fakeAuditService{},
+		userRepo.tokens, nil, nil, nil, mail, AuthConfig{
+			AppBaseURL:       "http://app.test",
+			PasswordResetTTL: time.Hour,
+		})
+
+	user, err := users.CreateUser(&dto.CreateUserDto{Username: "alice", Email: "alice@example.com", Password: "old password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := users.CreateUserSession(&domain.UserSession{UserID: user.ID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &passwordResetFixture{auth: auth, users: users, userRepo: userRepo, mail: mail, user: user}
+}
+
+func (f *passwordResetFixture) requestReset(t *testing.T) string {
+	t.Helper()
+	if err := f.auth.ForgotPassword(f.user.Email, dto.ClientInfoDto{}); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := f.mail.Messages()
+	if len(messages) == 0 {
+		t.Fatal("no reset email was sent")
+	}
+	message := messages[len(messages)-1]
+	if message.To != f.user.Email {
+		t.Fatalf("reset email sent to %q, want %q", message.To, f.user.Email)
+	}
+	match := resetLinkPattern.FindStringSubmatch(message.Body)
+	if match == nil {
+		t.Fatalf("reset email has no reset link: %q", message.Body)
+	}
+	return match[1]
+}
+
+func TestPasswordResetRevokesSessions(t *testing.T) {
+	f := newPasswordResetFixture(t)
+	token := f.requestReset(t)
+
+	if err := f.auth.ResetPassword(token, "new password", dto.ClientInfoDto{}); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := f.users.GetUserByID(uuid.MustParse(f.user.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.users.VerifyPassword(user, "new password") {
+		t.Error("new password is not accepted")
+	}
+	if f.users.VerifyPassword(user, "old password") {
+		t.Error("old password is still accepted")
+	}
+
+	sessions, err := f.users.GetUserSessions(uuid.MustParse(f.user.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("%d sessions survived the reset", len(sessions))
+	}
+
+	assertAppError(t, f.auth.ResetPassword(token, "another password", dto.ClientInfoDto{}), http.StatusBadRequest)
+}
+
+func TestPasswordResetKeepsTokenWhenPasswordIsRejected(t *testing.T) {
+	f := newPasswordResetFixture(t)
+	token := f.requestReset(t)
+
+	assertAppError(t, f.auth.ResetPassword(token, "short", dto.ClientInfoDto{}), http.StatusBadRequest)
+
+	sessions, err := f.users.GetUserSessions(uuid.MustParse(f.user.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Errorf("got %d sessions after a rejected reset, want 2", len(sessions))
+	}
+
+	if err := f.auth.ResetPassword(token, "new password", dto.ClientInfoDto{}); err != nil {
+		t.Fatalf("token was used up by the rejected reset: %v", err)
+	}
+}
+
+func TestForgotPasswordIgnoresUnknownEmail(t *testing.T) {
+	f := newPasswordResetFixture(t)
+	sent := len(f.mail.Messages())
+
+	if err := f.auth.ForgotPassword("nobody@example.com", dto.ClientInfoDto{}); err != nil {
+		t.Fatal(err)
+	}
+	if messages := f.mail.Messages(); len(messages) != sent {
+		t.Errorf("sent %d emails for an unknown address", len(messages)-sent)
+	}
+}
+
+type refreshFixture struct {
+	auth     *authServiceImpl
+	audit    *fakeAuditService
+	userRepo *fakeUserRepo
+	user     *domain.User
+	session  *domain.UserSession
+	token    string
+}
+
+func newRefreshFixture(t *testing.T) *refreshFixture {
+	t.Helper()
+	userRepo := newFakeUserRepo(newFakeUserTokenRepo())
+	users := testUserService(userRepo, mailer.NewMemoryMailer())
+	audit := &fakeAuditService{}
+	auth := NewAuthService(users, NewJwtService([]byte("access"), []byte("refresh")), audit,
+		userRepo.tokens, nil, nil, nil, nil, AuthConfig{RefreshTokenTTL: time.Hour}).(*authServiceImpl)
+
+	user, err := users.CreateUser(&dto.CreateUserDto{Username: "alice", Email: "alice@example.com", Password: "a password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshTokenID := uuid.NewString()
+	session, err := users.CreateUserSession(&domain.UserSession{UserID: user.ID, RefreshTokenID: &refreshTokenID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := auth.generateTokens(user, session.ID, refreshTokenID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &refreshFixture{auth: auth, audit: audit, userRepo: userRepo, user: user, session: session, token: tokens.RefreshToken}
+}
+
+func (f *refreshFixture) refresh(token string) (*dto.TokenResponseDto, error) {
+	return f.auth.RefreshToken(token, uuid.MustParse(f.user.ID), dto.ClientInfoDto{})
+}
+
+func (f *refreshFixture) assertRevoked(t *testing.T) {
+	t.Helper()
+	if _, err := f.userRepo.FindSessionByID(uuid.MustParse(f.session.ID)); err == nil {
+		t.Error("the session survived a reused refresh token")
+	}
+	for _, event := range f.audit.events {
+		if event.Type == domain.AuditEventRefreshTokenReuse {
+			return
+		}
+	}
+	t.Error("the reuse was not audited")
+}
+
+func TestReplayedRefreshTokenRevokesSession(t *testing.T) {
+	f := newRefreshFixture(t)
+
+	rotated, err := f.refresh(f.token)
+	if err != nil {
+		t.Fatalf("first refresh: %v", err)
+	}
+
+	_, err = f.refresh(f.token)
+	assertAppError(t, err, http.StatusForbidden)
+	f.assertRevoked(t)
+
+	// the family is gone, so the token issued by the rotation is dead too
+	_, err = f.refresh(rotated.RefreshToken)
+	assertAppError(t, err, http.StatusForbidden)
+}
+
+func TestConcurrentRefreshWithSameTokenRevokesSession(t *testing.T) {
+	f := newRefreshFixture(t)
+
+	// the other holder of the token rotates the session between this
+	// request's check and its update
+	var competing *dto.TokenResponseDto
+	var competingErr error
+	f.userRepo.beforeUpdateSession = func() {
+		f.userRepo.beforeUpdateSession = nil
+		competing, competingErr = f.refresh(f.token)
+	}
+
+	_, err := f.refresh(f.token)
+	if competingErr != nil || competing == nil {
+		t.Fatalf("competing refresh: %v", competingErr)
+	}
+	assertAppError(t, err, http.StatusForbidden)
+	f.assertRevoked(t)
+
+	_, err = f.refresh(competing.RefreshToken)
+	assertAppError(t, err, http.StatusForbidden)
+}
@@ -0,0 +1,498 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/mailer"
+	"github.com/ppondeu/go-post-api/internal/utils"
+	"gorm.io/gorm"
+)
+
+// Fakes of the larger interfaces embed the interface they stand in for, so a
+// test that reaches a method they do not implement panics instead of passing
+// silently.
+
+type fakeUserRepo struct {
+	domain.UserRepository
+	mu       sync.Mutex
+	users    map[string]*domain.User
+	sessions map[string]*domain.UserSession
+	tokens   *fakeUserTokenRepo
+	// beforeUpdateSession lets a test run a competing request at the
+	// moment a session is about to be rotated.
+	beforeUpdateSession func()
+}
+
+func newFakeUserRepo(tokens *fakeUserTokenRepo) *fakeUserRepo {
+	return &fakeUserRepo{
+		users:    make(map[string]*domain.User),
+		sessions: make(map[string]*domain.UserSession),
+		tokens:   tokens,
+	}
+}
+
+func (r *fakeUserRepo) Create(user *domain.User) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.users {
+		if existing.Email == user.Email || existing.Username == user.Username {
+			return nil, gorm.ErrDuplicatedKey
+		}
+	}
+	if user.ID == "" {
+		user.ID = uuid.New().String()
+	}
+	stored := *user
+	r.users[user.ID] = &stored
+	return user, nil
+}
+
+func (r *fakeUserRepo) FindByID(ID uuid.UUID) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[ID.String()]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+func (r *fakeUserRepo) FindByEmail(email string) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.Email == email {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) FindByUsername(username string) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.Username == username {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// Update copies the non-zero fields of user like GORM's Updates does and
+// unverifies a changed email like UserRepositoryDB.Update.
+func (r *fakeUserRepo) Update(ID uuid.UUID, user *domain.User) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.users[ID.String()]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if user.Username != "" {
+		existing.Username = user.Username
+	}
+	if user.Email != "" && user.Email != existing.Email {
+		existing.Email = user.Email
+		existing.EmailVerified = false
+	}
+	if user.Password != "" {
+		existing.Password = user.Password
+	}
+	if user.ShortBio != "" {
+		existing.ShortBio = user.ShortBio
+	}
+	updated := *existing
+	return &updated, nil
+}
+
+func (r *fakeUserRepo) SetEmailVerified(ID uuid.UUID, verified bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user, ok := r.users[ID.String()]; ok {
+		user.EmailVerified = verified
+	}
+	return nil
+}
+
+func (r *fakeUserRepo) ResetPassword(tokenID, ID uuid.UUID, password string) (bool, error) {
+	used, err := r.tokens.MarkUsed(tokenID)
+	if err != nil || !used {
+		return used, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[ID.String()].Password = password
+	return true, nil
+}
+
+func (r *fakeUserRepo) CreateSession(session *domain.UserSession) (*domain.UserSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if session.ID == "" {
+		session.ID = uuid.New().String()
+	}
+	stored := *session
+	r.sessions[session.ID] = &stored
+	return session, nil
+}
+
+func (r *fakeUserRepo) FindSessionsByUserID(userID uuid.UUID) ([]domain.UserSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sessions := make([]domain.UserSession, 0)
+	for _, session := range r.sessions {
+		if session.UserID == userID.String() {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *fakeUserRepo) FindSessionByID(ID uuid.UUID) (*domain.UserSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[ID.String()]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *session
+	return &found, nil
+}
+
+func (r *fakeUserRepo) UpdateSession(ID uuid.UUID, oldRefreshTokenID, refreshTokenID string) error {
+	if r.beforeUpdateSession != nil {
+		r.beforeUpdateSession()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[ID.String()]
+	if !ok || session.RefreshTokenID == nil || *session.RefreshTokenID != oldRefreshTokenID {
+		return gorm.ErrRecordNotFound
+	}
+	session.RefreshTokenID = &refreshTokenID
+	return nil
+}
+
+func (r *fakeUserRepo) DeleteSession(ID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, ID.String())
+	return nil
+}
+
+func (r *fakeUserRepo) DeleteSessionsByUserID(userID uuid.UUID, exceptID *uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for ID, session := range r.sessions {
+		if session.UserID == userID.String() && (exceptID == nil || ID != exceptID.String()) {
+			delete(r.sessions, ID)
+		}
+	}
+	return nil
+}
+
+type fakeUserTokenRepo struct {
+	mu     sync.Mutex
+	tokens map[string]*domain.UserToken
+}
+
+func newFakeUserTokenRepo() *fakeUserTokenRepo {
+	return &fakeUserTokenRepo{tokens: make(map[string]*domain.UserToken)}
+}
+
+func (r *fakeUserTokenRepo) Create(token *domain.UserToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token.ID = uuid.New().String()
+	stored := *token
+	r.tokens[token.ID] = &stored
+	return nil
+}
+
+func (r *fakeUserTokenRepo) FindByHash(purpose, tokenHash string) (*domain.UserToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.Purpose == purpose && token.TokenHash == tokenHash {
+			found := *token
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserTokenRepo) MarkUsed(ID uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[ID.String()]
+	if !ok || token.UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	return true, nil
+}
+
+func (r *fakeUserTokenRepo) DeleteByUserID(userID uuid.UUID, purpose string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for ID, token := range r.tokens {
+		if token.UserID == userID.String() && token.Purpose == purpose {
+			delete(r.tokens, ID)
+		}
+	}
+	return nil
+}
+
+type fakeAuditService struct {
+	AuditService
+	mu     sync.Mutex
+	events []domain.AuditEvent
+}
+
+func (s *fakeAuditService) Record(event domain.AuditEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+// testUserService wires a real UserService to in-memory repositories with
+// argon2id parameters cheap enough for tests.
+func testUserService(userRepo *fakeUserRepo, mail mailer.Mailer) UserService {
+	hasher := utils.NewArgon2idHasher(utils.Argon2idParams{
+		Memory:      1024,
+		Iterations:  1,
+		Parallelism: 1,
+		SaltLength:  utils.DefaultArgon2idParams.SaltLength,
+		KeyLength:   utils.DefaultArgon2idParams.KeyLength,
+	})
+	policy, err := utils.NewPasswordPolicy(8, 72, "")
+	if err != nil {
+		panic(err)
+	}
+	return NewUserService(userRepo, userRepo.tokens, &fakeAuditService{}, mail, hasher, policy, UserConfig{
+		AppBaseURL:           "http://app.test",
+		EmailVerificationTTL: time.Hour,
+		DeletionGracePeriod:  time.Hour,
+	})
+}
+
+type fakeExternalIdentityRepo struct {
+	mu         sync.Mutex
+	identities []domain.ExternalIdentity
+	states     map[string]domain.OIDCLoginState
+}
+
+func newFakeExternalIdentityRepo() *fakeExternalIdentityRepo {
+	return &fakeExternalIdentityRepo{states: make(map[string]domain.OIDCLoginState)}
+}
+
+func (r *fakeExternalIdentityRepo) Create(identity *domain.ExternalIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.identities {
+		if existing.Provider == identity.Provider && existing.Subject == identity.Subject {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	identity.ID = uuid.New().String()
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *fakeExternalIdentityRepo) FindByProviderSubject(provider, subject string) (*domain.ExternalIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			found := identity
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeExternalIdentityRepo) FindByUserID(userID uuid.UUID) ([]domain.ExternalIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	identities := make([]domain.ExternalIdentity, 0)
+	for _, identity := range r.identities {
+		if identity.UserID == userID.String() {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+func (r *fakeExternalIdentityRepo) CreateLoginState(state *domain.OIDCLoginState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.states[state.StateHash] = *state
+	return nil
+}
+
+func (r *fakeExternalIdentityRepo) ConsumeLoginState(stateHash string) (*domain.OIDCLoginState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.states[stateHash]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	delete(r.states, stateHash)
+	return &state, nil
+}
+
+func (r *fakeExternalIdentityRepo) DeleteExpiredLoginStates() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for stateHash, state := range r.states {
+		if state.ExpiresAt.Before(time.Now()) {
+			delete(r.states, stateHash)
+		}
+	}
+	return nil
+}
+
+type fakePostRepo struct {
+	domain.PostRepository
+	mu       sync.Mutex
+	posts    map[string]*domain.Post
+	comments map[string]*domain.Comment
+	tags     map[string]domain.Tag
+}
+
+func newFakePostRepo() *fakePostRepo {
+	return &fakePostRepo{
+		posts:    make(map[string]*domain.Post),
+		comments: make(map[string]*domain.Comment),
+		tags:     make(map[string]domain.Tag),
+	}
+}
+
+// add stores post as is, for tests that need a post in a given state.
+func (r *fakePostRepo) add(post domain.Post) uuid.UUID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if post.ID == "" {
+		post.ID = uuid.New().String()
+	}
+	r.posts[post.ID] = &post
+	return uuid.MustParse(post.ID)
+}
+
+func (r *fakePostRepo) addComment(comment domain.Comment) uuid.UUID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if comment.ID == "" {
+		comment.ID = uuid.New().String()
+	}
+	r.comments[comment.ID] = &comment
+	return uuid.MustParse(comment.ID)
+}
+
+func (r *fakePostRepo) FindCommentByID(ID uuid.UUID) (*domain.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	comment, ok := r.comments[ID.String()]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *comment
+	return &found, nil
+}
+
+func (r *fakePostRepo) FindRepliesByCommentID(ID uuid.UUID) ([]domain.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	replies := make([]domain.Comment, 0)
+	for _, comment := range r.comments {
+		if comment.ParentID != nil && *comment.ParentID == ID.String() {
+			replies = append(replies, *comment)
+		}
+	}
+	return replies, nil
+}
+
+func (r *fakePostRepo) FindByID(ID uuid.UUID) (*domain.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	post, ok := r.posts[ID.String()]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *post
+	return &found, nil
+}
+
+func (r *fakePostRepo) Update(ID uuid.UUID, post domain.Post) (*domain.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.posts[ID.String()]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if post.Title != "" {
+		stored.Title = post.Title
+	}
+	if post.Content != "" {
+		stored.Content = post.Content
+	}
+	if post.Tags != nil {
+		stored.Tags = post.Tags
+	}
+	if post.Status != "" {
+		stored.Status = post.Status
+		stored.PublishedAt = post.PublishedAt
+	}
+	updated := *stored
+	return &updated, nil
+}
+
+func (r *fakePostRepo) SetStatus(ID uuid.UUID, status domain.PostStatus, publishedAt *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	post, ok := r.posts[ID.String()]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	post.Status = status
+	post.PublishedAt = publishedAt
+	return nil
+}
+
+func (r *fakePostRepo) PublishDue(now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var published int64
+	for _, post := range r.posts {
+		if post.Status == domain.PostStatusScheduled && !post.PublishedAt.After(now) {
+			post.Status = domain.PostStatusPublished
+			published++
+		}
+	}
+	return published, nil
+}
+
+func (r *fakePostRepo) FindOrCreateTags(tags []domain.Tag) ([]domain.Tag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := make([]domain.Tag, 0, len(tags))
+	for _, tag := range tags {
+		existing, ok := r.tags[tag.Slug]
+		if !ok {
+			tag.ID = uuid.New().String()
+			r.tags[tag.Slug] = tag
+			existing = tag
+		}
+		stored = append(stored, existing)
+	}
+	return stored, nil
+}
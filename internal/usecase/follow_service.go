@@ -6,13 +6,14 @@ import (
 	"github.com/ppondeu/go-post-api/internal/dto"
 	"github.com/ppondeu/go-post-api/internal/errors"
 	"github.com/ppondeu/go-post-api/internal/logger"
+	"github.com/ppondeu/go-post-api/internal/presenter"
 )
 
 type FollowService interface {
 	Follow(followerID, followedID uuid.UUID) error
 	Unfollow(followerID, followedID uuid.UUID) error
-	GetFollowers(userID uuid.UUID) ([]dto.UserResponseDto, error)
-	GetFollowedUsers(userID uuid.UUID) ([]dto.UserResponseDto, error)
+	GetFollowers(userID uuid.UUID, page domain.PageRequest) (*domain.Page[dto.UserResponseDto], error)
+	GetFollowedUsers(userID uuid.UUID, page domain.PageRequest) (*domain.Page[dto.UserResponseDto], error)
 }
 
 type followServiceImpl struct {
@@ -82,15 +83,15 @@ func (s *followServiceImpl) Unfollow(followerID, followedID uuid.UUID) error {
 	return nil
 }
 
-func (s *followServiceImpl) GetFollowers(userID uuid.UUID) ([]dto.UserResponseDto, error) {
-	follows, err := s.followRepo.FindFollowersByUserID(userID)
+func (s *followServiceImpl) GetFollowers(userID uuid.UUID, page domain.PageRequest) (*domain.Page[dto.UserResponseDto], error) {
+	follows, err := s.followRepo.FindFollowersByUserID(userID, page)
 	if err != nil {
 		logger.Error(err)
 		return nil, err
 	}
 
-	var userResponseDtos []dto.UserResponseDto
-	for _, follow := range follows {
+	userResponseDtos := make([]dto.UserResponseDto, 0, len(follows.Items))
+	for _, follow := range follows.Items {
 		ID, err := uuid.Parse(follow.FollowerID)
 		if err != nil {
 			logger.Error(err)
@@ -101,24 +102,25 @@ func (s *followServiceImpl) GetFollowers(userID uuid.UUID) ([]dto.UserResponseDt
 			logger.Error(err)
 			return nil, err
 		}
-		userResponseDtos = append(userResponseDtos, dto.UserResponseDto{
-			ID:       user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-		})
+		userResponseDtos = append(userResponseDtos, presenter.User(*user))
 	}
-	return userResponseDtos, nil
+	return &domain.Page[dto.UserResponseDto]{
+		Items:      userResponseDtos,
+		Limit:      follows.Limit,
+		NextCursor: follows.NextCursor,
+		HasMore:    follows.HasMore,
+	}, nil
 }
 
-func (s *followServiceImpl) GetFollowedUsers(userID uuid.UUID) ([]dto.UserResponseDto, error) {
-	follows, err := s.followRepo.FindFollowedUsersByUserID(userID)
+func (s *followServiceImpl) GetFollowedUsers(userID uuid.UUID, page domain.PageRequest) (*domain.Page[dto.UserResponseDto], error) {
+	follows, err := s.followRepo.FindFollowedUsersByUserID(userID, page)
 	if err != nil {
 		logger.Error(err)
 		return nil, err
 	}
 
-	var userResponseDtos []dto.UserResponseDto
-	for _, follow := range follows {
+	userResponseDtos := make([]dto.UserResponseDto, 0, len(follows.Items))
+	for _, follow := range follows.Items {
 		ID, err := uuid.Parse(follow.FollowedID)
 		if err != nil {
 			logger.Error(err)
@@ -129,11 +131,12 @@ func (s *followServiceImpl) GetFollowedUsers(userID uuid.UUID) ([]dto.UserRespon
 			logger.Error(err)
 			return nil, err
 		}
-		userResponseDtos = append(userResponseDtos, dto.UserResponseDto{
-			ID:       user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-		})
+		userResponseDtos = append(userResponseDtos, presenter.User(*user))
 	}
-	return userResponseDtos, nil
+	return &domain.Page[dto.UserResponseDto]{
+		Items:      userResponseDtos,
+		Limit:      follows.Limit,
+		NextCursor: follows.NextCursor,
+		HasMore:    follows.HasMore,
+	}, nil
 }
@@ -2,33 +2,74 @@ package usecase
 
 import (
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/dto"
 	"github.com/ppondeu/go-post-api/internal/logger"
 )
 
 type JwtService interface {
 	GenerateToken(userClaims UserClaims, typeToken string) (*string, error)
+	GenerateImpersonationToken(user *domain.User, impersonatorID string, ttl time.Duration) (*string, *time.Time, error)
 	ValidateToken(tokenString string, typeToken string) (*UserClaims, error)
 	GetAccessSecret() []byte
 	GetRefreshSecret() []byte
+	GetJWKS() dto.JWKSDto
 }
 
 type jwtServiceImpl struct {
 	accessSecret  []byte
 	refreshSecret []byte
+	keys          map[string]SigningKey
+	activeKey     *SigningKey
 }
 
 func NewJwtService(accessSecret, refreshSecret []byte) JwtService {
-	return &jwtServiceImpl{accessSecret, refreshSecret}
+	return &jwtServiceImpl{accessSecret: accessSecret, refreshSecret: refreshSecret}
+}
+
+// NewAsymmetricJwtService signs access tokens with the key identified by
+// activeKeyID and accepts access tokens signed by any of keys. Refresh tokens
+// never leave this service, so they stay HS256 with refreshSecret.
+func NewAsymmetricJwtService(keys []SigningKey, activeKeyID string, refreshSecret []byte) (JwtService, error) {
+	service := &jwtServiceImpl{
+		refreshSecret: refreshSecret,
+		keys:          make(map[string]SigningKey),
+	}
+	for _, key := range keys {
+		service.keys[key.ID] = key
+	}
+
+	activeKey, ok := service.keys[activeKeyID]
+	if !ok {
+		return nil, fmt.Errorf("active signing key %q not found", activeKeyID)
+	}
+	if activeKey.PrivateKey == nil {
+		return nil, fmt.Errorf("active signing key %q has no private key", activeKeyID)
+	}
+	service.activeKey = &activeKey
+
+	return service, nil
 }
 
 func (s *jwtServiceImpl) GenerateToken(userClaims UserClaims, typeToken string) (*string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, userClaims)
-	var secret []byte
+	var token *jwt.Token
+	var secret interface{}
 	if typeToken == "access" {
-		secret = s.accessSecret
-	} else if typeToken == "refresh" {
+		if s.activeKey != nil {
+			token = jwt.NewWithClaims(s.activeKey.Method, userClaims)
+			token.Header["kid"] = s.activeKey.ID
+			secret = s.activeKey.PrivateKey
+		} else {
+			token = jwt.NewWithClaims(jwt.SigningMethodHS256, userClaims)
+			secret = s.accessSecret
+		}
+	} else if typeToken == "refresh" || typeToken == "mfa" {
+		token = jwt.NewWithClaims(jwt.SigningMethodHS256, userClaims)
 		secret = s.refreshSecret
 	} else {
 		return nil, fmt.Errorf("invalid token type")
@@ -42,15 +83,52 @@ func (s *jwtServiceImpl) GenerateToken(userClaims UserClaims, typeToken string)
 	return &tokenString, nil
 }
 
+// GenerateImpersonationToken issues an access token for user that also names
+// the admin behind it. It belongs to no session, so it cannot be refreshed and
+// ends at expiry.
+func (s *jwtServiceImpl) GenerateImpersonationToken(user *domain.User, impersonatorID string, ttl time.Duration) (*string, *time.Time, error) {
+	expiresAt := time.Now().Add(ttl)
+	userClaims := UserClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+		Sub:            user.ID,
+		Username:       user.Username,
+		TokenType:      "access",
+		Role:           string(user.Role),
+		ImpersonatorID: impersonatorID,
+	}
+
+	token, err := s.GenerateToken(userClaims, "access")
+	if err != nil {
+		return nil, nil, err
+	}
+	return token, &expiresAt, nil
+}
+
 func (s *jwtServiceImpl) ValidateToken(tokenString string, typeToken string) (*UserClaims, error) {
 	claims := &UserClaims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if typeToken == "access" && s.activeKey != nil {
+			kid, _ := token.Header["kid"].(string)
+			key, ok := s.keys[kid]
+			if !ok {
+				return nil, fmt.Errorf("unknown signing key: %v", kid)
+			}
+			if token.Method.Alg() != key.Method.Alg() {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return key.PublicKey, nil
+		}
+
 		if token.Header["alg"] != "HS256" {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		if typeToken == "access" {
 			return s.accessSecret, nil
-		} else if typeToken == "refresh" {
+		} else if typeToken == "refresh" || typeToken == "mfa" {
 			return s.refreshSecret, nil
 		} else {
 			return nil, fmt.Errorf("invalid token type")
@@ -80,3 +158,14 @@ func (s *jwtServiceImpl) GetAccessSecret() []byte {
 func (s *jwtServiceImpl) GetRefreshSecret() []byte {
 	return s.refreshSecret
 }
+
+func (s *jwtServiceImpl) GetJWKS() dto.JWKSDto {
+	jwks := dto.JWKSDto{Keys: make([]dto.JWKDto, 0, len(s.keys))}
+	for _, key := range s.keys {
+		jwks.Keys = append(jwks.Keys, key.JWK())
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+	return jwks
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/errors"
+	"github.com/ppondeu/go-post-api/internal/logger"
+	"gorm.io/gorm"
+)
+
+type LoginThrottleConfig struct {
+	MaxAccountAttempts int
+	MaxIPAttempts      int
+	Window             time.Duration
+	BaseLockout        time.Duration
+	MaxLockout         time.Duration
+}
+
+type LoginThrottleService interface {
+	Check(email, ip string) error
+	RecordFailure(email, ip string) error
+	Reset(email string) error
+}
+
+type loginThrottleServiceImpl struct {
+	loginAttemptRepo domain.LoginAttemptRepository
+	config           LoginThrottleConfig
+}
+
+func NewLoginThrottleService(loginAttemptRepo domain.LoginAttemptRepository, config LoginThrottleConfig) LoginThrottleService {
+	return &loginThrottleServiceImpl{
+		loginAttemptRepo: loginAttemptRepo,
+		config:           config,
+	}
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func (s *loginThrottleServiceImpl) Check(email, ip string) error {
+	for _, key := range []string{accountKey(email), ipKey(ip)} {
+		attempt, err := s.loginAttemptRepo.FindByKey(key)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				continue
+			}
+			logger.Error(err)
+			return err
+		}
+
+		if attempt.LockedUntil != nil && time.Now().Before(*attempt.LockedUntil) {
+			return errors.NewTooManyRequestsError("Too many failed login attempts, try again later")
+		}
+	}
+	return nil
+}
+
+func (s *loginThrottleServiceImpl) RecordFailure(email, ip string) error {
+	if err := s.recordFailure(accountKey(email), s.config.MaxAccountAttempts); err != nil {
+		return err
+	}
+	return s.recordFailure(ipKey(ip), s.config.MaxIPAttempts)
+}
+
+func (s *loginThrottleServiceImpl) recordFailure(key string, maxAttempts int) error {
+	now := time.Now()
+	attempt, err := s.loginAttemptRepo.FindByKey(key)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logger.Error(err)
+			return err
+		}
+		attempt = &domain.LoginAttempt{Key: key}
+	}
+
+	locked := attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil)
+	if !locked && now.Sub(attempt.LastFailureAt) > s.config.Window {
+		attempt.Failures = 0
+	}
+
+	attempt.Failures++
+	attempt.LastFailureAt = now
+	if attempt.Failures >= maxAttempts {
+		lockedUntil := now.Add(s.lockoutDuration(attempt.Failures - maxAttempts))
+		attempt.LockedUntil = &lockedUntil
+	}
+
+	err = s.loginAttemptRepo.Save(attempt)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (s *loginThrottleServiceImpl) lockoutDuration(excess int) time.Duration {
+	lockout := s.config.BaseLockout
+	for i := 0; i < excess && lockout < s.config.MaxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > s.config.MaxLockout {
+		lockout = s.config.MaxLockout
+	}
+	return lockout
+}
+
+func (s *loginThrottleServiceImpl) Reset(email string) error {
+	err := s.loginAttemptRepo.Delete(accountKey(email))
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
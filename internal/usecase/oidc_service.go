@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/errors"
+	"github.com/ppondeu/go-post-api/internal/logger"
+	"github.com/ppondeu/go-post-api/internal/utils"
+	"gorm.io/gorm"
+)
+
+// OIDCLoginStateTTL is how long a login started at the identity provider can
+// take to come back.
+const OIDCLoginStateTTL = 10 * time.Minute
+
+type OIDCService interface {
+	// AuthorizationURL also returns the state it put in the URL, which the
+	// callback has to come back with.
+	AuthorizationURL(providerName string) (authURL, state string, err error)
+	Authenticate(providerName, code, state string) (*domain.User, error)
+}
+
+type oidcServiceImpl struct {
+	providers    map[string]*oidcProvider
+	identityRepo domain.ExternalIdentityRepository
+	userService  UserService
+}
+
+func NewOIDCService(configs []OIDCProviderConfig, identityRepo domain.ExternalIdentityRepository, userService UserService) OIDCService {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	providers := make(map[string]*oidcProvider)
+	for _, config := range configs {
+		providers[config.Name] = newOIDCProvider(config, httpClient)
+	}
+
+	return &oidcServiceImpl{
+		providers:    providers,
+		identityRepo: identityRepo,
+		userService:  userService,
+	}
+}
+
+func (s *oidcServiceImpl) getProvider(providerName string) (*oidcProvider, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, errors.NewNotFoundError("Identity provider not found")
+	}
+	return provider, nil
+}
+
+func (s *oidcServiceImpl) AuthorizationURL(providerName string) (string, string, error) {
+	provider, err := s.getProvider(providerName)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.identityRepo.DeleteExpiredLoginStates(); err != nil {
+		logger.Error(err)
+	}
+
+	var values [3]string
+	for i := range values {
+		values[i], err = utils.GenerateRandomToken()
+		if err != nil {
+			logger.Error(err)
+			return "", "", errors.NewInternalServerError()
+		}
+	}
+	state, nonce, codeVerifier := values[0], values[1], values[2]
+
+	loginState := &domain.OIDCLoginState{
+		StateHash:    utils.HashToken(state),
+		Provider:     providerName,
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(OIDCLoginStateTTL),
+	}
+	err = s.identityRepo.CreateLoginState(loginState)
+	if err != nil {
+		logger.Error(err)
+		return "", "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(state, nonce, codeVerifier)
+	if err != nil {
+		logger.Error(err)
+		return "", "", errors.NewInternalServerError()
+	}
+	return authURL, state, nil
+}
+
+func (s *oidcServiceImpl) Authenticate(providerName, code, state string) (*domain.User, error) {
+	provider, err := s.getProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	loginState, err := s.identityRepo.ConsumeLoginState(utils.HashToken(state))
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logger.Error(err)
+			return nil, err
+		}
+		return nil, errors.NewUnauthorizedError("Invalid or expired login state")
+	}
+
+	if loginState.Provider != providerName || time.Now().After(loginState.ExpiresAt) {
+		return nil, errors.NewUnauthorizedError("Invalid or expired login state")
+	}
+
+	rawIDToken, err := provider.Exchange(code, loginState.CodeVerifier)
+	if err != nil {
+		logger.Error(err)
+		return nil, errors.NewUnauthorizedError("Identity provider login failed")
+	}
+
+	claims, err := provider.VerifyIDToken(rawIDToken, loginState.Nonce)
+	if err != nil {
+		logger.Error(err)
+		return nil, errors.NewUnauthorizedError("Identity provider login failed")
+	}
+
+	return s.resolveUser(providerName, claims)
+}
+
+// resolveUser returns the user already linked to the identity, links it to
+// the user with the same verified email, or creates a new user.
+func (s *oidcServiceImpl) resolveUser(providerName string, claims *OIDCIDTokenClaims) (*domain.User, error) {
+	identity, err := s.identityRepo.FindByProviderSubject(providerName, claims.Subject)
+	if err == nil {
+		return s.userService.GetUserByID(uuid.MustParse(identity.UserID))
+	}
+	if err != gorm.ErrRecordNotFound {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if claims.Email == "" || !bool(claims.EmailVerified) {
+		logger.Error("identity provider did not return a verified email address")
+		return nil, errors.NewForbiddenError("Identity provider did not return a verified email address")
+	}
+
+	user, err := s.userService.GetUserByEmail(claims.Email)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != http.StatusNotFound {
+			return nil, err
+		}
+
+		user, err = s.createUser(claims)
+		if err != nil {
+			return nil, err
+		}
+	} else if !user.EmailVerified {
+		// Linking to an unverified account would hand it to whoever registered the address first.
+		logger.Error("refusing to link identity to an account with an unverified email")
+		return nil, errors.NewConflictError("An account with this email already exists, verify its email address before signing in with this provider")
+	}
+
+	identity = &domain.ExternalIdentity{
+		UserID:   user.ID,
+		Provider: providerName,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+	err = s.identityRepo.Create(identity)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+var usernameInvalidChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+func (s *oidcServiceImpl) createUser(claims *OIDCIDTokenClaims) (*domain.User, error) {
+	base := claims.PreferredUsername
+	if base == "" || strings.Contains(base, "@") {
+		base = strings.SplitN(claims.Email, "@", 2)[0]
+	}
+	base = usernameInvalidChars.ReplaceAllString(strings.ToLower(base), "")
+	if len(base) > 24 {
+		base = base[:24]
+	}
+	if len(base) < 3 {
+		base = "user"
+	}
+
+	username := base
+	for i := 0; i < 5; i++ {
+		_, err := s.userService.GetUserByUsername(username)
+		if err != nil {
+			if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != http.StatusNotFound {
+				return nil, err
+			}
+			return s.userService.CreateExternalUser(username, claims.Email)
+		}
+
+		suffix, err := utils.GenerateRandomToken()
+		if err != nil {
+			logger.Error(err)
+			return nil, errors.NewInternalServerError()
+		}
+		username = base + "-" + strings.ToLower(suffix[:6])
+	}
+	return nil, errors.NewConflictError("Could not pick a unique username")
+}
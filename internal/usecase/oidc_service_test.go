@@ -0,0 +1,361 @@
+package usecase
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/dto"
+	"github.com/ppondeu/go-post-api/internal/errors"
+)
+
+const (
+	fakeIdPProvider     = "fake"
+	fakeIdPClientID     = "post-api"
+	fakeIdPClientSecret = "client secret"
+	fakeIdPRedirectURL  = "http://app.test/api/auth/oidc/fake/callback"
+)
+
+// fakeIdP is an in-process OpenID provider serving discovery, JWKS and a
+// token endpoint that enforces PKCE and client authentication.
+type fakeIdP struct {
+	server *httptest.Server
+	key    SigningKey
+
+	mu     sync.Mutex
+	grants map[string]fakeIdPGrant
+	// id tokens are signed with signingKey when it is set
+	signingKey *SigningKey
+	tamper     func(claims *OIDCIDTokenClaims)
+}
+
+type fakeIdPGrant struct {
+	claims        OIDCIDTokenClaims
+	codeChallenge string
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	t.Helper()
+	idp := &fakeIdP{
+		key:    newTestSigningKey(t, "idp-key"),
+		grants: make(map[string]fakeIdPGrant),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusOK, oidcDiscovery{
+			Issuer:                idp.server.URL,
+			AuthorizationEndpoint: idp.server.URL + "/authorize",
+			TokenEndpoint:         idp.server.URL + "/token",
+			JwksURI:               idp.server.URL + "/jwks",
+			TokenAuthMethods:      []string{"client_secret_basic"},
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusOK, dto.JWKSDto{Keys: []dto.JWKDto{idp.key.JWK()}})
+	})
+	mux.HandleFunc("POST /token", idp.token)
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func newTestSigningKey(t *testing.T, kid string) SigningKey {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return SigningKey{ID: kid, Method: jwt.SigningMethodEdDSA, PrivateKey: privateKey, PublicKey: publicKey}
+}
+
+func writeTestJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (idp *fakeIdP) token(w http.ResponseWriter, r *http.Request) {
+	// RFC 6749 form-encodes the client credentials before basic auth
+	clientID, clientSecret, ok := r.BasicAuth()
+	clientID, _ = url.QueryUnescape(clientID)
+	clientSecret, _ = url.QueryUnescape(clientSecret)
+	if !ok || clientID != fakeIdPClientID || clientSecret != fakeIdPClientSecret {
+		writeTestJSON(w, http.StatusUnauthorized, oidcTokenResponse{Error: "invalid_client"})
+		return
+	}
+	if r.FormValue("grant_type") != "authorization_code" || r.FormValue("redirect_uri") != fakeIdPRedirectURL {
+		writeTestJSON(w, http.StatusBadRequest, oidcTokenResponse{Error: "invalid_request"})
+		return
+	}
+
+	idp.mu.Lock()
+	grant, ok := idp.grants[r.FormValue("code")]
+	delete(idp.grants, r.FormValue("code"))
+	signingKey, tamper := idp.key, idp.tamper
+	if idp.signingKey != nil {
+		signingKey = *idp.signingKey
+	}
+	idp.mu.Unlock()
+
+	if !ok || pkceChallenge(r.FormValue("code_verifier")) != grant.codeChallenge {
+		writeTestJSON(w, http.StatusBadRequest, oidcTokenResponse{Error: "invalid_grant"})
+		return
+	}
+
+	claims := grant.claims
+	if tamper != nil {
+		tamper(&claims)
+	}
+	token := jwt.NewWithClaims(signingKey.Method, claims)
+	token.Header["kid"] = signingKey.ID
+	idToken, err := token.SignedString(signingKey.PrivateKey)
+	if err != nil {
+		writeTestJSON(w, http.StatusInternalServerError, oidcTokenResponse{Error: "server_error"})
+		return
+	}
+	writeTestJSON(w, http.StatusOK, oidcTokenResponse{IDToken: idToken})
+}
+
+// authorize plays the user approving the login at authURL and returns the
+// code and state the provider redirects back with.
+func (idp *fakeIdP) authorize(t *testing.T, authURL, subject, email string, emailVerified bool) (string, string) {
+	t.Helper()
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatalf("authorization URL is missing a PKCE challenge: %s", authURL)
+	}
+
+	now := time.Now()
+	code := subject + "-" + query.Get("state")[:8]
+	idp.mu.Lock()
+	idp.grants[code] = fakeIdPGrant{
+		codeChallenge: query.Get("code_challenge"),
+		claims: OIDCIDTokenClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    idp.server.URL,
+				Subject:   subject,
+				Audience:  jwt.ClaimStrings{fakeIdPClientID},
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+			},
+			Nonce:         query.Get("nonce"),
+			Email:         email,
+			EmailVerified: oidcBool(emailVerified),
+		},
+	}
+	idp.mu.Unlock()
+	return code, query.Get("state")
+}
+
+type oidcFixture struct {
+	idp          *fakeIdP
+	oidc         OIDCService
+	users        UserService
+	userRepo     *fakeUserRepo
+	identityRepo *fakeExternalIdentityRepo
+}
+
+func newOIDCFixture(t *testing.T) *oidcFixture {
+	t.Helper()
+	idp := newFakeIdP(t)
+	userRepo := newFakeUserRepo(newFakeUserTokenRepo())
+	users := testUserService(userRepo, nil)
+	identityRepo := newFakeExternalIdentityRepo()
+	oidc := NewOIDCService([]OIDCProviderConfig{{
+		Name:         fakeIdPProvider,
+		Issuer:       idp.server.URL,
+		ClientID:     fakeIdPClientID,
+		ClientSecret: fakeIdPClientSecret,
+		RedirectURL:  fakeIdPRedirectURL,
+	}}, identityRepo, users)
+	return &oidcFixture{idp: idp, oidc: oidc, users: users, userRepo: userRepo, identityRepo: identityRepo}
+}
+
+func (f *oidcFixture) login(t *testing.T, subject, email string, emailVerified bool) (*domain.User, error) {
+	t.Helper()
+	authURL, _, err := f.oidc.AuthorizationURL(fakeIdPProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state := f.idp.authorize(t, authURL, subject, email, emailVerified)
+	return f.oidc.Authenticate(fakeIdPProvider, code, state)
+}
+
+func assertAppError(t *testing.T, err error, status int) {
+	t.Helper()
+	appErr, ok := err.(*errors.AppError)
+	if !ok || appErr.Code != status {
+		t.Fatalf("got error %v, want status %d", err, status)
+	}
+}
+
+func TestOIDCAuthorizationURLUsesDiscovery(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	authURL, state, err := f.oidc.AuthorizationURL(fakeIdPProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.Scheme + "://" + parsed.Host + parsed.Path; got != f.idp.server.URL+"/authorize" {
+		t.Errorf("authorization endpoint is %s, want the discovered one", got)
+	}
+	query := parsed.Query()
+	if query.Get("client_id") != fakeIdPClientID || query.Get("redirect_uri") != fakeIdPRedirectURL {
+		t.Errorf("unexpected client parameters: %v", query)
+	}
+	if query.Get("state") == "" || query.Get("nonce") == "" || query.Get("state") == query.Get("nonce") {
+		t.Errorf("state and nonce must be present and distinct: %v", query)
+	}
+	if query.Get("state") != state {
+		t.Errorf("returned state %q is not the one in the URL %q", state, query.Get("state"))
+	}
+
+	_, _, err = f.oidc.AuthorizationURL("unknown")
+	assertAppError(t, err, http.StatusNotFound)
+}
+
+func TestOIDCLoginCreatesVerifiedUser(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	user, err := f.login(t, "subject-1", "Bob.Smith@example.com", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !user.EmailVerified || user.Email != "Bob.Smith@example.com" || user.Username != "bob.smith" {
+		t.Errorf("unexpected user %+v", user)
+	}
+
+	again, err := f.login(t, "subject-1", "Bob.Smith@example.com", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != user.ID {
+		t.Errorf("second login resolved user %s, want %s", again.ID, user.ID)
+	}
+}
+
+func TestOIDCLoginLinksAccountByVerifiedEmail(t *testing.T) {
+	f := newOIDCFixture(t)
+	existing, err := f.userRepo.Create(&domain.User{Username: "carol", Email: "carol@example.com", EmailVerified: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := f.login(t, "subject-2", "carol@example.com", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != existing.ID {
+		t.Fatalf("logged in as %s, want the existing account %s", user.ID, existing.ID)
+	}
+
+	identity, err := f.identityRepo.FindByProviderSubject(fakeIdPProvider, "subject-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.UserID != existing.ID {
+		t.Errorf("identity linked to %s, want %s", identity.UserID, existing.ID)
+	}
+}
+
+func TestOIDCLoginRefusesUnverifiedEmail(t *testing.T) {
+	f := newOIDCFixture(t)
+	if _, err := f.userRepo.Create(&domain.User{Username: "dave", Email: "dave@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := f.login(t, "subject-3", "dave@example.com", true)
+	assertAppError(t, err, http.StatusConflict)
+
+	_, err = f.login(t, "subject-4", "erin@example.com", false)
+	assertAppError(t, err, http.StatusForbidden)
+
+	if len(f.identityRepo.identities) != 0 {
+		t.Errorf("linked %d identities without a verified email", len(f.identityRepo.identities))
+	}
+}
+
+func TestOIDCLoginRejectsInvalidIDTokens(t *testing.T) {
+	forgedKey := newTestSigningKey(t, "idp-key")
+
+	tests := []struct {
+		name       string
+		signingKey *SigningKey
+		tamper     func(claims *OIDCIDTokenClaims)
+	}{
+		{name: "signature from another key", signingKey: &forgedKey},
+		{name: "nonce mismatch", tamper: func(claims *OIDCIDTokenClaims) { claims.Nonce = "replayed" }},
+		{name: "wrong audience", tamper: func(claims *OIDCIDTokenClaims) { claims.Audience = jwt.ClaimStrings{"someone-else"} }},
+		{name: "wrong issuer", tamper: func(claims *OIDCIDTokenClaims) { claims.Issuer = "https://evil.example.com" }},
+		{name: "expired", tamper: func(claims *OIDCIDTokenClaims) {
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		}},
+		{name: "no subject", tamper: func(claims *OIDCIDTokenClaims) { claims.Subject = "" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newOIDCFixture(t)
+			f.idp.mu.Lock()
+			f.idp.signingKey = tt.signingKey
+			f.idp.tamper = tt.tamper
+			f.idp.mu.Unlock()
+
+			_, err := f.login(t, "subject-5", "frank@example.com", true)
+			assertAppError(t, err, http.StatusUnauthorized)
+			if len(f.userRepo.users) != 0 {
+				t.Error("a user was created from an invalid id token")
+			}
+		})
+	}
+}
+
+func TestOIDCLoginRejectsInvalidState(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	authURL, _, err := f.oidc.AuthorizationURL(fakeIdPProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state := f.idp.authorize(t, authURL, "subject-6", "grace@example.com", true)
+
+	_, err = f.oidc.Authenticate(fakeIdPProvider, code, "forged-state")
+	assertAppError(t, err, http.StatusUnauthorized)
+
+	if _, err := f.oidc.Authenticate(fakeIdPProvider, code, state); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = f.oidc.Authenticate(fakeIdPProvider, code, state)
+	assertAppError(t, err, http.StatusUnauthorized)
+}
+
+func TestOIDCLoginRejectsFailedCodeExchange(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	authURL, _, err := f.oidc.AuthorizationURL(fakeIdPProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, state := f.idp.authorize(t, authURL, "subject-7", "heidi@example.com", true)
+
+	_, err = f.oidc.Authenticate(fakeIdPProvider, "unknown-code", state)
+	assertAppError(t, err, http.StatusUnauthorized)
+}
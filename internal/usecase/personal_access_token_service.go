@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/dto"
+	"github.com/ppondeu/go-post-api/internal/errors"
+	"github.com/ppondeu/go-post-api/internal/logger"
+	"github.com/ppondeu/go-post-api/internal/utils"
+	"gorm.io/gorm"
+)
+
+const PersonalAccessTokenPrefix = "pat_"
+
+type PersonalAccessTokenService interface {
+	CreateToken(userID uuid.UUID, createTokenDto dto.CreatePersonalAccessTokenDto) (*dto.PersonalAccessTokenCreatedDto, error)
+	GetTokens(userID uuid.UUID) ([]dto.PersonalAccessTokenResponseDto, error)
+	RevokeToken(userID, ID uuid.UUID) error
+	ValidateToken(token string) (*UserClaims, error)
+}
+
+type personalAccessTokenServiceImpl struct {
+	tokenRepo   domain.PersonalAccessTokenRepository
+	userService UserService
+}
+
+func NewPersonalAccessTokenService(tokenRepo domain.PersonalAccessTokenRepository, userService UserService) PersonalAccessTokenService {
+	return &personalAccessTokenServiceImpl{
+		tokenRepo:   tokenRepo,
+		userService: userService,
+	}
+}
+
+func toPersonalAccessTokenResponse(token domain.PersonalAccessToken) dto.PersonalAccessTokenResponseDto {
+	return dto.PersonalAccessTokenResponseDto{
+		ID:         token.ID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     token.Scopes,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		CreatedAt:  token.CreatedAt,
+	}
+}
+
+func (s *personalAccessTokenServiceImpl) CreateToken(userID uuid.UUID, createTokenDto dto.CreatePersonalAccessTokenDto) (*dto.PersonalAccessTokenCreatedDto, error) {
+	_, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	random, err := utils.GenerateRandomToken()
+	if err != nil {
+		logger.Error(err)
+		return nil, errors.NewInternalServerError()
+	}
+	rawToken := PersonalAccessTokenPrefix + random
+
+	token := &domain.PersonalAccessToken{
+		UserID:    userID.String(),
+		Name:      createTokenDto.Name,
+		Prefix:    rawToken[:len(PersonalAccessTokenPrefix)+6],
+		TokenHash: utils.HashToken(rawToken),
+		Scopes:    createTokenDto.Scopes,
+		CreatedAt: time.Now(),
+	}
+	if createTokenDto.ExpiresInDays != nil {
+		expiresAt := time.Now().AddDate(0, 0, *createTokenDto.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	err = s.tokenRepo.Create(token)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	return &dto.PersonalAccessTokenCreatedDto{
+		PersonalAccessTokenResponseDto: toPersonalAccessTokenResponse(*token),
+		Token:                          rawToken,
+	}, nil
+}
+
+func (s *personalAccessTokenServiceImpl) GetTokens(userID uuid.UUID) ([]dto.PersonalAccessTokenResponseDto, error) {
+	tokens, err := s.tokenRepo.FindByUserID(userID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	tokenResponseDtos := make([]dto.PersonalAccessTokenResponseDto, 0, len(tokens))
+	for _, token := range tokens {
+		tokenResponseDtos = append(tokenResponseDtos, toPersonalAccessTokenResponse(token))
+	}
+	return tokenResponseDtos, nil
+}
+
+func (s *personalAccessTokenServiceImpl) RevokeToken(userID, ID uuid.UUID) error {
+	token, err := s.tokenRepo.FindByID(ID)
+	if err != nil {
+		logger.Error(err)
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewNotFoundError("Token not found")
+		}
+		return err
+	}
+
+	if token.UserID != userID.String() {
+		return errors.NewNotFoundError("Token not found")
+	}
+
+	err = s.tokenRepo.Delete(ID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (s *personalAccessTokenServiceImpl) ValidateToken(rawToken string) (*UserClaims, error) {
+	if !strings.HasPrefix(rawToken, PersonalAccessTokenPrefix) {
+		return nil, errors.NewUnauthorizedError("Invalid access token")
+	}
+
+	token, err := s.tokenRepo.FindByHash(utils.HashToken(rawToken))
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logger.Error(err)
+		}
+		return nil, errors.NewUnauthorizedError("Invalid access token")
+	}
+
+	if token.ExpiresAt != nil && time.Now().After(*token.ExpiresAt) {
+		return nil, errors.NewUnauthorizedError("Access token expired")
+	}
+
+	user, err := s.userService.GetUserByID(uuid.MustParse(token.UserID))
+	if err != nil || user.DeletionDueAt != nil {
+		return nil, errors.NewUnauthorizedError("Invalid access token")
+	}
+
+	if err := s.tokenRepo.UpdateLastUsed(uuid.MustParse(token.ID)); err != nil {
+		logger.Error(err)
+	}
+
+	return &UserClaims{
+		Sub:       user.ID,
+		Username:  user.Username,
+		TokenType: "pat",
+		Scopes:    token.Scopes,
+	}, nil
+}
@@ -1,50 +1,151 @@
 package usecase
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/ppondeu/go-post-api/internal/domain"
 	"github.com/ppondeu/go-post-api/internal/dto"
 	"github.com/ppondeu/go-post-api/internal/errors"
 	"github.com/ppondeu/go-post-api/internal/logger"
+	"github.com/ppondeu/go-post-api/internal/utils"
 	"gorm.io/gorm"
 )
 
 type PostService interface {
-	GetAllPosts() ([]domain.Post, error)
+	GetAllPosts(filter domain.PostFilter, page domain.PageRequest) (*domain.Page[domain.Post], error)
 	GetPostByID(ID uuid.UUID) (*domain.Post, error)
-	GetPostsByUserID(userID uuid.UUID) ([]domain.Post, error)
-	CreatePost(post dto.CreatePostDto) (*domain.Post, error)
-	UpdatePost(ID uuid.UUID, post dto.UpdatePostDto) (*domain.Post, error)
-	DeletePost(ID uuid.UUID) error
+	GetPostForViewer(viewerID, ID uuid.UUID) (*domain.Post, error)
+	GetPostsByUserID(userID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.Post], error)
+	GetMyPosts(userID uuid.UUID, status domain.PostStatus, page domain.PageRequest) (*domain.Page[domain.Post], error)
+	CreatePost(userID uuid.UUID, post dto.CreatePostDto) (*domain.Post, error)
+	UpdatePost(userID, ID uuid.UUID, post dto.UpdatePostDto) (*domain.Post, error)
+	DeletePost(userID, ID uuid.UUID) error
+	PublishPost(userID, ID uuid.UUID, publishAt *time.Time) (*domain.Post, error)
+	PublishScheduledPosts() error
+
+	GetRevisions(userID, postID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.PostRevision], error)
+	DiffRevisions(userID, postID uuid.UUID, from, to int) (*dto.PostRevisionDiffDto, error)
+	RestoreRevision(userID, postID uuid.UUID, number int) (*domain.Post, error)
 
 	GetAllTags() ([]domain.Tag, error)
+	GetTagBySlug(slug string) (*domain.Tag, error)
 	AddBookmark(userID, PostID uuid.UUID) error
 	RemoveBookmark(userID, PostID uuid.UUID) error
 	LikePost(userID, PostID uuid.UUID) error
 	UnlikePost(userID, PostID uuid.UUID) error
 
-	AddComment(createCommentDto dto.CreateCommentDto) (*domain.Comment, error)
-	UpdateComment(commentID uuid.UUID, content string) (*domain.Comment, error)
-	DeleteComment(commentID uuid.UUID) error
-	GetCommentsByPost(postID uuid.UUID) ([]domain.Comment, error)
-	GetCommentByID(commentID uuid.UUID) (*domain.Comment, error)
+	AddComment(userID uuid.UUID, createCommentDto dto.CreateCommentDto) (*domain.Comment, error)
+	UpdateComment(userID, commentID uuid.UUID, content string) (*domain.Comment, error)
+	DeleteComment(userID, commentID uuid.UUID) error
+
+	GetDeletedPosts(userID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.Post], error)
+	GetDeletedComments(userID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.Comment], error)
+	RestorePost(userID, ID uuid.UUID) (*domain.Post, error)
+	RestoreComment(userID, commentID uuid.UUID) (*domain.Comment, error)
+	PurgeTrash() error
+	GetCommentsByPost(viewerID, postID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.Comment], error)
+	GetCommentByID(viewerID, commentID uuid.UUID) (*domain.Comment, error)
+}
+
+type PostConfig struct {
+	RequireVerifiedEmail bool
+	// TrashRetention is how long deleted posts and comments can be restored.
+	TrashRetention time.Duration
+	// Now defaults to time.Now
+	Now func() time.Time
 }
 
+// maxDiffLines bounds the quadratic line diff between two revisions.
+const maxDiffLines = 2000
+
 type postServiceImpl struct {
-	postRepo    domain.PostRepository
-	userService UserService
+	postRepo     domain.PostRepository
+	revisionRepo domain.PostRevisionRepository
+	userService  UserService
+	config       PostConfig
 }
 
-func NewPostService(postRepo domain.PostRepository, userService UserService) PostService {
+func NewPostService(postRepo domain.PostRepository, revisionRepo domain.PostRevisionRepository, userService UserService, config PostConfig) PostService {
+	if config.Now == nil {
+		config.Now = time.Now
+	}
 	return &postServiceImpl{
-		postRepo:    postRepo,
-		userService: userService,
+		postRepo:     postRepo,
+		revisionRepo: revisionRepo,
+		userService:  userService,
+		config:       config,
+	}
+}
+
+func (p *postServiceImpl) checkCanPublish(user *domain.User) error {
+	if p.config.RequireVerifiedEmail && !user.EmailVerified {
+		logger.Error("Email address must be verified")
+		return errors.NewForbiddenError("Email address must be verified")
+	}
+	return nil
+}
+
+func (p *postServiceImpl) checkPermission(userID uuid.UUID, permission domain.Permission, message string) error {
+	user, err := p.userService.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.Role.HasPermission(permission) {
+		logger.Error(message)
+		return errors.NewForbiddenError(message)
 	}
+	return nil
+}
+
+// resolveTags normalizes the requested tags, drops duplicates and returns the
+// stored tags, creating any that do not exist yet.
+func (p *postServiceImpl) resolveTags(names []string) ([]domain.Tag, error) {
+	tags := make([]domain.Tag, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, tagName := range names {
+		name, slug := utils.NormalizeTag(tagName)
+		if slug == "" {
+			logger.Error("Tag must contain a letter or digit")
+			return nil, errors.NewBadRequestError("Tag must contain a letter or digit")
+		}
+		if len(slug) > domain.MaxTagSlugLength {
+			logger.Error("Tag is too long")
+			return nil, errors.NewBadRequestError(fmt.Sprintf("Tag must be at most %d characters", domain.MaxTagSlugLength))
+		}
+		if seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		tags = append(tags, domain.Tag{Name: name, Slug: slug})
+	}
+
+	if len(tags) > domain.MaxTagsPerPost {
+		logger.Error("Too many tags")
+		return nil, errors.NewBadRequestError(fmt.Sprintf("A post can have at most %d tags", domain.MaxTagsPerPost))
+	}
+
+	storedTags, err := p.postRepo.FindOrCreateTags(tags)
+	if err != nil {
+		logger.Error(err)
+		return nil, errors.NewInternalServerError()
+	}
+	return storedTags, nil
 }
 
-func (p *postServiceImpl) GetAllPosts() ([]domain.Post, error) {
-	posts, err := p.postRepo.FindAll()
+func (p *postServiceImpl) GetAllPosts(filter domain.PostFilter, page domain.PageRequest) (*domain.Page[domain.Post], error) {
+	filter.Status = domain.PostStatusPublished
+	if filter.Tag != "" {
+		_, filter.Tag = utils.NormalizeTag(filter.Tag)
+	}
+
+	posts, err := p.postRepo.FindAll(filter, page)
 	if err != nil {
+		logger.Error(err)
 		return nil, err
 	}
 
@@ -63,29 +164,79 @@ func (p *postServiceImpl) GetPostByID(ID uuid.UUID) (*domain.Post, error) {
 	return post, nil
 }
 
-func (p *postServiceImpl) GetPostsByUserID(userID uuid.UUID) ([]domain.Post, error) {
-	posts, err := p.postRepo.FindByUserID(userID)
+// GetPostForViewer hides unpublished posts from everyone but their author.
+// viewerID is uuid.Nil for anonymous requests.
+func (p *postServiceImpl) GetPostForViewer(viewerID, ID uuid.UUID) (*domain.Post, error) {
+	post, err := p.GetPostByID(ID)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, errors.NewNotFoundError("Posts not found")
-		}
-		return nil, errors.NewBadRequestError(err.Error())
+		return nil, err
+	}
+
+	if post.Status != domain.PostStatusPublished && post.UserID != viewerID.String() {
+		return nil, errors.NewNotFoundError("Post not found")
+	}
+	return post, nil
+}
+
+// getPublishedPost is for actions other users take on a post, which are only
+// possible once it is public.
+func (p *postServiceImpl) getPublishedPost(ID uuid.UUID) (*domain.Post, error) {
+	return p.GetPostForViewer(uuid.Nil, ID)
+}
+
+func (p *postServiceImpl) GetMyPosts(userID uuid.UUID, status domain.PostStatus, page domain.PageRequest) (*domain.Page[domain.Post], error) {
+	posts, err := p.postRepo.FindAll(domain.PostFilter{AuthorID: &userID, Status: status}, page)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
 	}
 
 	return posts, nil
 }
 
-func (p *postServiceImpl) CreatePost(postDto dto.CreatePostDto) (*domain.Post, error) {
+func (p *postServiceImpl) GetPostsByUserID(userID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.Post], error) {
+	return p.GetAllPosts(domain.PostFilter{AuthorID: &userID}, page)
+}
+
+func (p *postServiceImpl) CreatePost(userID uuid.UUID, postDto dto.CreatePostDto) (*domain.Post, error) {
+	user, err := p.userService.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := domain.PostStatus(postDto.Status)
+	if status == "" {
+		status = domain.PostStatusPublished
+	}
+	publishedAt := p.config.Now()
+	if status == domain.PostStatusScheduled {
+		if postDto.PublishAt == nil || !postDto.PublishAt.After(publishedAt) {
+			logger.Error("publishAt must be in the future")
+			return nil, errors.NewBadRequestError("publishAt must be in the future")
+		}
+		publishedAt = *postDto.PublishAt
+	}
+
+	if status != domain.PostStatusDraft {
+		if err := p.checkCanPublish(user); err != nil {
+			return nil, err
+		}
+	}
+
+	tags, err := p.resolveTags(postDto.Tags)
+	if err != nil {
+		return nil, err
+	}
 
 	newPost := domain.Post{
 		Title:   postDto.Title,
 		Content: postDto.Content,
-		UserID:  postDto.UserID,
-		Tags:    postDto.Tags,
+		UserID:  userID.String(),
+		Tags:    tags,
+		Status:  status,
 	}
-
-	if len(postDto.Tags) > 0 {
-		postDto.Tags = []string{}
+	if status != domain.PostStatusDraft {
+		newPost.PublishedAt = &publishedAt
 	}
 
 	post, err := p.postRepo.Save(newPost)
@@ -97,17 +248,37 @@ func (p *postServiceImpl) CreatePost(postDto dto.CreatePostDto) (*domain.Post, e
 	return post, nil
 }
 
-func (p *postServiceImpl) UpdatePost(ID uuid.UUID, postDto dto.UpdatePostDto) (*domain.Post, error) {
+func (p *postServiceImpl) UpdatePost(userID, ID uuid.UUID, postDto dto.UpdatePostDto) (*domain.Post, error) {
+	existingPost, err := p.GetPostByID(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingPost.UserID != userID.String() {
+		logger.Error("You can't update another user's post")
+		return nil, errors.NewForbiddenError("You can't update another user's post")
+	}
+
+	// leaving tags out keeps them, an empty list clears them
+	var tags []domain.Tag
+	if postDto.Tags != nil {
+		tags, err = p.resolveTags(postDto.Tags)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	updatePost := domain.Post{
 		Title:   postDto.Title,
 		Content: postDto.Content,
-		Tags:    postDto.Tags,
+		Tags:    tags,
 	}
-
-	if updatePost.Tags == nil {
-		updatePost.Tags = []string{}
-	} else {
-		updatePost.Tags = postDto.Tags
+	if postDto.Status != "" {
+		updatePost.Status = domain.PostStatus(postDto.Status)
+		// archived posts remember when they were published, drafts were never
+		if updatePost.Status != domain.PostStatusDraft {
+			updatePost.PublishedAt = existingPost.PublishedAt
+		}
 	}
 
 	post, err := p.postRepo.Update(ID, updatePost)
@@ -122,8 +293,19 @@ func (p *postServiceImpl) UpdatePost(ID uuid.UUID, postDto dto.UpdatePostDto) (*
 	return post, nil
 }
 
-func (p *postServiceImpl) DeletePost(ID uuid.UUID) error {
-	err := p.postRepo.Delete(ID)
+func (p *postServiceImpl) DeletePost(userID, ID uuid.UUID) error {
+	post, err := p.GetPostByID(ID)
+	if err != nil {
+		return err
+	}
+
+	if post.UserID != userID.String() {
+		if err := p.checkPermission(userID, domain.PermissionDeleteAnyPost, "You can't delete another user's post"); err != nil {
+			return err
+		}
+	}
+
+	err = p.postRepo.Delete(ID, userID)
 	if err != nil {
 		logger.Error(err)
 		return err
@@ -132,6 +314,154 @@ func (p *postServiceImpl) DeletePost(ID uuid.UUID) error {
 	return nil
 }
 
+func (p *postServiceImpl) PublishPost(userID, ID uuid.UUID, publishAt *time.Time) (*domain.Post, error) {
+	post, err := p.GetPostByID(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.UserID != userID.String() {
+		logger.Error("You can't publish another user's post")
+		return nil, errors.NewForbiddenError("You can't publish another user's post")
+	}
+	if post.Status == domain.PostStatusPublished {
+		logger.Error("Post is already published")
+		return nil, errors.NewBadRequestError("Post is already published")
+	}
+
+	user, err := p.userService.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkCanPublish(user); err != nil {
+		return nil, err
+	}
+
+	status := domain.PostStatusPublished
+	publishedAt := p.config.Now()
+	if publishAt != nil && publishAt.After(publishedAt) {
+		status = domain.PostStatusScheduled
+		publishedAt = *publishAt
+	} else if post.Status == domain.PostStatusArchived && post.PublishedAt != nil {
+		// an archived post comes back with its original date
+		publishedAt = *post.PublishedAt
+	}
+
+	if err := p.postRepo.SetStatus(ID, status, &publishedAt); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return p.GetPostByID(ID)
+}
+
+func (p *postServiceImpl) PublishScheduledPosts() error {
+	published, err := p.postRepo.PublishDue(p.config.Now())
+	if err != nil {
+		return err
+	}
+	if published > 0 {
+		logger.Info(fmt.Sprintf("Published %d scheduled posts", published))
+	}
+	return nil
+}
+
+// getOwnPost returns the post if userID wrote it. Revisions are only shown to
+// the author, since they can include text from before the post went public.
+func (p *postServiceImpl) getOwnPost(userID, ID uuid.UUID) (*domain.Post, error) {
+	post, err := p.GetPostByID(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.UserID != userID.String() {
+		logger.Error("You can't view another user's revisions")
+		return nil, errors.NewForbiddenError("You can't view another user's revisions")
+	}
+	return post, nil
+}
+
+func (p *postServiceImpl) getRevision(postID uuid.UUID, number int) (*domain.PostRevision, error) {
+	revision, err := p.revisionRepo.FindByNumber(postID, number)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError(fmt.Sprintf("Revision %d not found", number))
+		}
+		logger.Error(err)
+		return nil, errors.NewInternalServerError()
+	}
+	return revision, nil
+}
+
+func (p *postServiceImpl) GetRevisions(userID, postID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.PostRevision], error) {
+	if _, err := p.getOwnPost(userID, postID); err != nil {
+		return nil, err
+	}
+
+	revisions, err := p.revisionRepo.FindByPostID(postID, page)
+	if err != nil {
+		logger.Error(err)
+		return nil, errors.NewInternalServerError()
+	}
+	return revisions, nil
+}
+
+func (p *postServiceImpl) DiffRevisions(userID, postID uuid.UUID, from, to int) (*dto.PostRevisionDiffDto, error) {
+	if _, err := p.getOwnPost(userID, postID); err != nil {
+		return nil, err
+	}
+
+	fromRevision, err := p.getRevision(postID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := p.getRevision(postID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.Count(fromRevision.Content, "\n") >= maxDiffLines || strings.Count(toRevision.Content, "\n") >= maxDiffLines {
+		logger.Error("Revisions are too long to compare")
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Revisions longer than %d lines can't be compared", maxDiffLines))
+	}
+
+	diff := &dto.PostRevisionDiffDto{
+		From:    from,
+		To:      to,
+		Content: make([]dto.DiffLineDto, 0),
+	}
+	if fromRevision.Title != toRevision.Title {
+		diff.Title = &dto.FieldChangeDto{From: fromRevision.Title, To: toRevision.Title}
+	}
+	if !slices.Equal(fromRevision.Tags, toRevision.Tags) {
+		diff.Tags = &dto.TagsChangeDto{From: fromRevision.Tags, To: toRevision.Tags}
+	}
+	for _, line := range utils.DiffLines(fromRevision.Content, toRevision.Content) {
+		diff.Content = append(diff.Content, dto.DiffLineDto{Op: line.Op, Text: line.Text})
+	}
+	return diff, nil
+}
+
+// RestoreRevision copies an old revision back into the post, which records it
+// again as the newest revision.
+func (p *postServiceImpl) RestoreRevision(userID, postID uuid.UUID, number int) (*domain.Post, error) {
+	if _, err := p.getOwnPost(userID, postID); err != nil {
+		return nil, err
+	}
+
+	revision, err := p.getRevision(postID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	// a revision without tags scans as nil, which UpdatePost would read as
+	// "keep the current tags"
+	return p.UpdatePost(userID, postID, dto.UpdatePostDto{
+		Title:   revision.Title,
+		Content: revision.Content,
+		Tags:    append([]string{}, revision.Tags...),
+	})
+}
+
 func (p *postServiceImpl) GetAllTags() ([]domain.Tag, error) {
 	tags, err := p.postRepo.FindAllTags()
 	if err != nil {
@@ -141,12 +471,26 @@ func (p *postServiceImpl) GetAllTags() ([]domain.Tag, error) {
 	return tags, nil
 }
 
+func (p *postServiceImpl) GetTagBySlug(slug string) (*domain.Tag, error) {
+	_, slug = utils.NormalizeTag(slug)
+	tag, err := p.postRepo.FindTagBySlug(slug)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("Tag not found")
+		}
+		logger.Error(err)
+		return nil, errors.NewInternalServerError()
+	}
+
+	return tag, nil
+}
+
 func (p *postServiceImpl) AddBookmark(userID, PostID uuid.UUID) error {
 	_, err := p.userService.GetUserByID(userID)
 	if err != nil {
 		return err
 	}
-	post, err := p.GetPostByID(PostID)
+	post, err := p.getPublishedPost(PostID)
 	if err != nil {
 		return err
 	}
@@ -198,7 +542,7 @@ func (p *postServiceImpl) LikePost(userID, PostID uuid.UUID) error {
 	if err != nil {
 		return err
 	}
-	post, err := p.GetPostByID(PostID)
+	post, err := p.getPublishedPost(PostID)
 	if err != nil {
 		return err
 	}
@@ -246,28 +590,29 @@ func (p *postServiceImpl) UnlikePost(userID, PostID uuid.UUID) error {
 	return nil
 }
 
-func (p *postServiceImpl) AddComment(createCommentDto dto.CreateCommentDto) (*domain.Comment, error) {
-	userID, err := uuid.Parse(createCommentDto.UserID)
+func (p *postServiceImpl) AddComment(userID uuid.UUID, createCommentDto dto.CreateCommentDto) (*domain.Comment, error) {
+	PostID, err := uuid.Parse(createCommentDto.PostID)
 	if err != nil {
 		return nil, err
 	}
-	PostID, err := uuid.Parse(createCommentDto.PostID)
+
+	user, err := p.userService.GetUserByID(userID)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = p.userService.GetUserByID(userID)
-	if err != nil {
+	if err := p.checkCanPublish(user); err != nil {
 		return nil, err
 	}
-	_, err = p.GetPostByID(PostID)
+
+	_, err = p.getPublishedPost(PostID)
 	if err != nil {
 		return nil, err
 	}
 
 	comment := domain.Comment{
 		Content:  createCommentDto.Content,
-		UserID:   createCommentDto.UserID,
+		UserID:   userID.String(),
 		PostID:   createCommentDto.PostID,
 		ParentID: createCommentDto.ParentID,
 	}
@@ -281,7 +626,16 @@ func (p *postServiceImpl) AddComment(createCommentDto dto.CreateCommentDto) (*do
 	return newComment, nil
 }
 
-func (p *postServiceImpl) UpdateComment(commentID uuid.UUID, content string) (*domain.Comment, error) {
+func (p *postServiceImpl) UpdateComment(userID, commentID uuid.UUID, content string) (*domain.Comment, error) {
+	existingComment, err := p.getComment(commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingComment.UserID != userID.String() {
+		logger.Error("You can't update another user's comment")
+		return nil, errors.NewForbiddenError("You can't update another user's comment")
+	}
 
 	comment := domain.Comment{
 		Content: content,
@@ -296,8 +650,19 @@ func (p *postServiceImpl) UpdateComment(commentID uuid.UUID, content string) (*d
 	return updatedComment, nil
 }
 
-func (p *postServiceImpl) DeleteComment(commentID uuid.UUID) error {
-	err := p.postRepo.DeleteComment(commentID)
+func (p *postServiceImpl) DeleteComment(userID, commentID uuid.UUID) error {
+	comment, err := p.getComment(commentID)
+	if err != nil {
+		return err
+	}
+
+	if comment.UserID != userID.String() {
+		if err := p.checkPermission(userID, domain.PermissionDeleteAnyComment, "You can't delete another user's comment"); err != nil {
+			return err
+		}
+	}
+
+	err = p.postRepo.DeleteComment(commentID, userID)
 	if err != nil {
 		logger.Error(err)
 		return err
@@ -306,27 +671,47 @@ func (p *postServiceImpl) DeleteComment(commentID uuid.UUID) error {
 	return nil
 }
 
-func (p *postServiceImpl) GetCommentsByPost(postID uuid.UUID) ([]domain.Comment, error) {
-	comments, err := p.postRepo.FindCommentsByPostID(postID)
+func (p *postServiceImpl) GetCommentsByPost(viewerID, postID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.Comment], error) {
+	if _, err := p.GetPostForViewer(viewerID, postID); err != nil {
+		return nil, err
+	}
+
+	comments, err := p.postRepo.FindCommentsByPostID(postID, page)
 	if err != nil {
 		logger.Error(err)
 		return nil, err
 	}
 
 	var replies []domain.Comment
-	for i := range comments {
-		replies, err = p.postRepo.FindRepliesByCommentID(uuid.MustParse(comments[i].ID))
+	for i := range comments.Items {
+		replies, err = p.postRepo.FindRepliesByCommentID(uuid.MustParse(comments.Items[i].ID))
 		if err != nil {
 			logger.Error(err)
 			return nil, err
 		}
-		comments[i].Replies = replies
+		comments.Items[i].Replies = replies
 	}
 
 	return comments, nil
 }
 
-func (p *postServiceImpl) GetCommentByID(commentID uuid.UUID) (*domain.Comment, error) {
+// GetCommentByID hides the comments of unpublished posts like
+// GetPostForViewer hides the posts.
+func (p *postServiceImpl) GetCommentByID(viewerID, commentID uuid.UUID) (*domain.Comment, error) {
+	comment, err := p.getComment(commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := p.GetPostForViewer(viewerID, uuid.MustParse(comment.PostID)); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// getComment is for the comment's author and moderators, who act on it
+// whatever the status of its post.
+func (p *postServiceImpl) getComment(commentID uuid.UUID) (*domain.Comment, error) {
 	comment, err := p.postRepo.FindCommentByID(commentID)
 	if err != nil {
 		logger.Error(err)
@@ -346,3 +731,83 @@ func (p *postServiceImpl) GetCommentByID(commentID uuid.UUID) (*domain.Comment,
 
 	return comment, nil
 }
+
+func (p *postServiceImpl) GetDeletedPosts(userID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.Post], error) {
+	posts, err := p.postRepo.FindDeleted(userID, page)
+	if err != nil {
+		logger.Error(err)
+		return nil, errors.NewInternalServerError()
+	}
+	return posts, nil
+}
+
+func (p *postServiceImpl) GetDeletedComments(userID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.Comment], error) {
+	comments, err := p.postRepo.FindDeletedComments(userID, page)
+	if err != nil {
+		logger.Error(err)
+		return nil, errors.NewInternalServerError()
+	}
+	return comments, nil
+}
+
+// checkCanRestore lets whoever deleted an item restore it, and moderators
+// restore anything, so authors can't undo a moderator's deletion.
+func (p *postServiceImpl) checkCanRestore(userID uuid.UUID, deletedBy *string, permission domain.Permission, message string) error {
+	if deletedBy != nil && *deletedBy == userID.String() {
+		return nil
+	}
+	return p.checkPermission(userID, permission, message)
+}
+
+func (p *postServiceImpl) RestorePost(userID, ID uuid.UUID) (*domain.Post, error) {
+	post, err := p.postRepo.FindDeletedByID(ID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("Post not found in trash")
+		}
+		logger.Error(err)
+		return nil, errors.NewInternalServerError()
+	}
+
+	if err := p.checkCanRestore(userID, post.DeletedBy, domain.PermissionDeleteAnyPost, "You can't restore this post"); err != nil {
+		return nil, err
+	}
+
+	if err := p.postRepo.Restore(ID); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return p.GetPostByID(ID)
+}
+
+func (p *postServiceImpl) RestoreComment(userID, commentID uuid.UUID) (*domain.Comment, error) {
+	comment, err := p.postRepo.FindDeletedCommentByID(commentID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("Comment not found in trash")
+		}
+		logger.Error(err)
+		return nil, errors.NewInternalServerError()
+	}
+
+	if err := p.checkCanRestore(userID, comment.DeletedBy, domain.PermissionDeleteAnyComment, "You can't restore this comment"); err != nil {
+		return nil, err
+	}
+
+	if err := p.postRepo.RestoreComment(commentID); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return p.getComment(commentID)
+}
+
+func (p *postServiceImpl) PurgeTrash() error {
+	purged, err := p.postRepo.PurgeDeleted(p.config.Now().Add(-p.config.TrashRetention))
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		logger.Info(fmt.Sprintf("Purged %d deleted posts and comments", purged))
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/dto"
+	"github.com/ppondeu/go-post-api/internal/mailer"
+)
+
+// fakeClock is a PostConfig.Now that only moves when told to.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestPostService(postRepo *fakePostRepo, clock *fakeClock) (PostService, uuid.UUID) {
+	userRepo := newFakeUserRepo(newFakeUserTokenRepo())
+	author, err := userRepo.Create(&domain.User{Username: "author", Email: "author@example.com", Role: domain.RoleUser})
+	if err != nil {
+		panic(err)
+	}
+	users := testUserService(userRepo, mailer.NewMemoryMailer())
+	return NewPostService(postRepo, nil, users, PostConfig{Now: clock.Now}), uuid.MustParse(author.ID)
+}
+
+func TestUpdatePostTags(t *testing.T) {
+	postRepo := newFakePostRepo()
+	service := NewPostService(postRepo, nil, nil, PostConfig{})
+	authorID := uuid.New()
+	postID := postRepo.add(domain.Post{
+		UserID:  authorID.String(),
+		Title:   "title",
+		Content: "content",
+		Tags:    []domain.Tag{{ID: uuid.NewString(), Name: "go", Slug: "go"}},
+		Status:  domain.PostStatusDraft,
+	})
+
+	post, err := service.UpdatePost(authorID, postID, dto.UpdatePostDto{Title: "new title"})
+	if err != nil {
+		t.Fatalf("UpdatePost without tags: %v", err)
+	}
+	if len(post.Tags) != 1 || post.Tags[0].Slug != "go" {
+		t.Errorf("leaving tags out changed them to %v", post.Tags)
+	}
+
+	post, err = service.UpdatePost(authorID, postID, dto.UpdatePostDto{Tags: []string{"Go Lang", "go"}})
+	if err != nil {
+		t.Fatalf("UpdatePost with tags: %v", err)
+	}
+	if len(post.Tags) != 2 || post.Tags[0].Slug != "go-lang" || post.Tags[1].Slug != "go" {
+		t.Errorf("tags are %v, want go-lang and go", post.Tags)
+	}
+
+	post, err = service.UpdatePost(authorID, postID, dto.UpdatePostDto{Tags: []string{}})
+	if err != nil {
+		t.Fatalf("UpdatePost with no tags: %v", err)
+	}
+	if len(post.Tags) != 0 {
+		t.Errorf("an empty list left tags %v", post.Tags)
+	}
+}
+
+func TestScheduledPostIsPublishedOnceItsTimeHasCome(t *testing.T) {
+	postRepo := newFakePostRepo()
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	service, authorID := newTestPostService(postRepo, clock)
+	postID := postRepo.add(domain.Post{UserID: authorID.String(), Title: "title", Content: "content", Status: domain.PostStatusDraft})
+
+	publishAt := clock.Now().Add(time.Hour)
+	post, err := service.PublishPost(authorID, postID, &publishAt)
+	if err != nil {
+		t.Fatalf("PublishPost: %v", err)
+	}
+	if post.Status != domain.PostStatusScheduled || !post.PublishedAt.Equal(publishAt) {
+		t.Fatalf("post is %s at %v, want scheduled at %v", post.Status, post.PublishedAt, publishAt)
+	}
+
+	for _, step := range []struct {
+		advance time.Duration
+		status  domain.PostStatus
+	}{
+		{0, domain.PostStatusScheduled},
+		{time.Hour - time.Second, domain.PostStatusScheduled},
+		{time.Second, domain.PostStatusPublished},
+	} {
+		clock.Advance(step.advance)
+		if err := service.PublishScheduledPosts(); err != nil {
+			t.Fatalf("PublishScheduledPosts: %v", err)
+		}
+		post, err := service.GetPostByID(postID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if post.Status != step.status {
+			t.Errorf("at %v the post is %s, want %s", clock.Now(), post.Status, step.status)
+		}
+	}
+
+	post, _ = service.GetPostByID(postID)
+	if !post.PublishedAt.Equal(publishAt) {
+		t.Errorf("published at %v, want the scheduled %v", post.PublishedAt, publishAt)
+	}
+}
+
+func TestRepublishingArchivedPostKeepsPublishedAt(t *testing.T) {
+	postRepo := newFakePostRepo()
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	service, authorID := newTestPostService(postRepo, clock)
+	publishedAt := clock.Now().Add(-30 * 24 * time.Hour)
+	postID := postRepo.add(domain.Post{
+		UserID: authorID.String(), Title: "title", Content: "content",
+		Status: domain.PostStatusPublished, PublishedAt: &publishedAt,
+	})
+
+	post, err := service.UpdatePost(authorID, postID, dto.UpdatePostDto{Status: string(domain.PostStatusArchived)})
+	if err != nil {
+		t.Fatalf("archiving: %v", err)
+	}
+	if post.Status != domain.PostStatusArchived || post.PublishedAt == nil || !post.PublishedAt.Equal(publishedAt) {
+		t.Fatalf("archived post is %s at %v, want archived at %v", post.Status, post.PublishedAt, publishedAt)
+	}
+
+	post, err = service.PublishPost(authorID, postID, nil)
+	if err != nil {
+		t.Fatalf("republishing: %v", err)
+	}
+	if post.Status != domain.PostStatusPublished || !post.PublishedAt.Equal(publishedAt) {
+		t.Errorf("republished post is %s at %v, want published at %v", post.Status, post.PublishedAt, publishedAt)
+	}
+}
+
+func TestMovingPostToDraftClearsPublishedAt(t *testing.T) {
+	postRepo := newFakePostRepo()
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	service, authorID := newTestPostService(postRepo, clock)
+	publishedAt := clock.Now()
+	postID := postRepo.add(domain.Post{
+		UserID: authorID.String(), Title: "title", Content: "content",
+		Status: domain.PostStatusPublished, PublishedAt: &publishedAt,
+	})
+
+	post, err := service.UpdatePost(authorID, postID, dto.UpdatePostDto{Content: "rewritten", Status: string(domain.PostStatusDraft)})
+	if err != nil {
+		t.Fatalf("UpdatePost: %v", err)
+	}
+	if post.Status != domain.PostStatusDraft || post.PublishedAt != nil || post.Content != "rewritten" {
+		t.Errorf("post is %s at %v with %q, want a rewritten draft without a date", post.Status, post.PublishedAt, post.Content)
+	}
+}
+
+func TestCommentsOfArchivedPostAreHiddenFromOtherUsers(t *testing.T) {
+	postRepo := newFakePostRepo()
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	service, authorID := newTestPostService(postRepo, clock)
+	publishedAt := clock.Now()
+	postID := postRepo.add(domain.Post{
+		UserID: authorID.String(), Title: "title", Content: "content",
+		Status: domain.PostStatusArchived, PublishedAt: &publishedAt,
+	})
+	commentID := postRepo.addComment(domain.Comment{UserID: uuid.NewString(), PostID: postID.String(), Content: "comment"})
+
+	for name, viewerID := range map[string]uuid.UUID{"anonymous": uuid.Nil, "another user": uuid.New()} {
+		if _, err := service.GetCommentByID(viewerID, commentID); err == nil {
+			t.Errorf("%s can read a comment of an archived post", name)
+		}
+		if _, err := service.GetCommentsByPost(viewerID, postID, domain.PageRequest{}); err == nil {
+			t.Errorf("%s can list the comments of an archived post", name)
+		}
+	}
+
+	if _, err := service.GetCommentByID(authorID, commentID); err != nil {
+		t.Errorf("the post's author can't read its comment: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/dto"
+	"github.com/ppondeu/go-post-api/internal/errors"
+	"github.com/ppondeu/go-post-api/internal/logger"
+	"github.com/ppondeu/go-post-api/internal/utils"
+)
+
+type SearchService interface {
+	Search(searchQueryDto dto.SearchQueryDto, page domain.PageRequest) (*domain.Page[domain.SearchResult], error)
+}
+
+type searchServiceImpl struct {
+	searchRepo domain.SearchRepository
+}
+
+func NewSearchService(searchRepo domain.SearchRepository) SearchService {
+	return &searchServiceImpl{
+		searchRepo: searchRepo,
+	}
+}
+
+// parseSearchTerms splits text into "quoted phrases", prefix* terms and plain
+// words. Prefix terms keep only letters and digits so they are always a valid
+// tsquery lexeme.
+func parseSearchTerms(text string) []domain.SearchTerm {
+	var terms []domain.SearchTerm
+	for i, part := range strings.Split(text, `"`) {
+		// odd parts were between quotes
+		if i%2 == 1 {
+			if phrase := strings.TrimSpace(part); phrase != "" {
+				terms = append(terms, domain.SearchTerm{Text: phrase, Phrase: true})
+			}
+			continue
+		}
+
+		for _, word := range strings.Fields(part) {
+			if strings.HasSuffix(word, "*") {
+				word = strings.Map(func(r rune) rune {
+					if unicode.IsLetter(r) || unicode.IsDigit(r) {
+						return r
+					}
+					return -1
+				}, word)
+				if word != "" {
+					terms = append(terms, domain.SearchTerm{Text: word, Prefix: true})
+				}
+				continue
+			}
+			terms = append(terms, domain.SearchTerm{Text: word})
+		}
+	}
+	return terms
+}
+
+func (s *searchServiceImpl) Search(searchQueryDto dto.SearchQueryDto, page domain.PageRequest) (*domain.Page[domain.SearchResult], error) {
+	query := domain.SearchQuery{
+		Terms: parseSearchTerms(searchQueryDto.Q),
+	}
+	if searchQueryDto.Tag != "" {
+		_, query.Tag = utils.NormalizeTag(searchQueryDto.Tag)
+	}
+	if searchQueryDto.Type != "" {
+		query.Types = []string{searchQueryDto.Type}
+	}
+	if searchQueryDto.Author != "" {
+		authorID, err := uuid.Parse(searchQueryDto.Author)
+		if err != nil {
+			return nil, errors.NewBadRequestError("author is invalid")
+		}
+		query.AuthorID = &authorID
+	}
+
+	if len(query.Terms) == 0 {
+		logger.Error("search query is empty")
+		return nil, errors.NewBadRequestError("search query is empty")
+	}
+
+	results, err := s.searchRepo.Search(query, page)
+	if err != nil {
+		logger.Error(err)
+		return nil, errors.NewInternalServerError()
+	}
+	return results, nil
+}
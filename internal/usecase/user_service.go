@@ -2,13 +2,15 @@ package usecase
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/ppondeu/go-post-api/internal/domain"
 	"github.com/ppondeu/go-post-api/internal/dto"
 	"github.com/ppondeu/go-post-api/internal/errors"
 	"github.com/ppondeu/go-post-api/internal/logger"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/ppondeu/go-post-api/internal/mailer"
+	"github.com/ppondeu/go-post-api/internal/utils"
 	"gorm.io/gorm"
 )
 
@@ -16,25 +18,116 @@ type UserService interface {
 	GetUserByID(ID uuid.UUID) (*domain.User, error)
 	GetUserByUsername(username string) (*domain.User, error)
 	GetUserByEmail(email string) (*domain.User, error)
-	GetAllUsers() ([]domain.User, error)
+	GetAllUsers(page domain.PageRequest) (*domain.Page[domain.User], error)
 	GetUserWithRelation(ID uuid.UUID) (*domain.User, error)
 	GetUsersWithRelation() ([]domain.User, error)
 	CreateUser(createUserDto *dto.CreateUserDto) (*domain.User, error)
-	UpdateUser(ID uuid.UUID, updateUserDto *dto.UpdateUserDto) (*domain.User, error)
-	UpdateUserSession(userID uuid.UUID, refreshToken *string) error
-	CreateUserAndSession(createUserDto *dto.CreateUserDto, refreshToken *string) (*domain.User, error)
-	GetUserSession(userID uuid.UUID) (*domain.UserSession, error)
-	DeleteUser(ID uuid.UUID) error
+	UpdateUser(actorID, ID, sessionID uuid.UUID, updateUserDto *dto.UpdateUserDto, clientInfo dto.ClientInfoDto) (*domain.User, error)
+	ResetPassword(tokenID, ID uuid.UUID, password string, clientInfo dto.ClientInfoDto) error
+	CreateUserAndSession(createUserDto *dto.CreateUserDto, session *domain.UserSession) (*domain.User, error)
+	CreateExternalUser(username, email string) (*domain.User, error)
+	VerifyPassword(user *domain.User, password string) bool
+	DeleteUser(actorID, ID uuid.UUID, clientInfo dto.ClientInfoDto) error
+	UpdateUserRole(actorID, ID uuid.UUID, role domain.Role, clientInfo dto.ClientInfoDto) (*domain.User, error)
 
-	GetUserBookmarks(userID uuid.UUID) ([]domain.Bookmark, error)
+	RequestDeletion(ID uuid.UUID, clientInfo dto.ClientInfoDto) (*time.Time, error)
+	CancelDeletion(user *domain.User, clientInfo dto.ClientInfoDto) error
+	PurgeDeletedUsers() error
+
+	UpdateTwoFactor(ID uuid.UUID, secret *string, enabled bool) error
+	SetTwoFactorLastStep(ID uuid.UUID, step int64) error
+
+	SendEmailVerification(user *domain.User) error
+	VerifyEmail(token string, clientInfo dto.ClientInfoDto) error
+
+	CreateUserSession(session *domain.UserSession) (*domain.UserSession, error)
+	GetUserSession(ID uuid.UUID) (*domain.UserSession, error)
+	GetUserSessions(userID uuid.UUID) ([]domain.UserSession, error)
+	UpdateUserSession(ID uuid.UUID, oldRefreshTokenID, refreshTokenID string) error
+	DeleteUserSession(ID uuid.UUID) error
+	DeleteUserSessions(userID uuid.UUID, exceptID *uuid.UUID) error
+
+	GetUserBookmarks(userID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.Bookmark], error)
+}
+
+type UserConfig struct {
+	AppBaseURL           string
+	EmailVerificationTTL time.Duration
+	DeletionGracePeriod  time.Duration
 }
 
 type UserServiceImpl struct {
-	userRepo domain.UserRepository
+	userRepo          domain.UserRepository
+	userTokenRepo     domain.UserTokenRepository
+	auditService      AuditService
+	mailer            mailer.Mailer
+	passwordHasher    utils.PasswordHasher
+	passwordPolicy    *utils.PasswordPolicy
+	config            UserConfig
+	dummyPasswordHash string
+}
+
+func NewUserService(userRepo domain.UserRepository, userTokenRepo domain.UserTokenRepository, auditService AuditService, mailer mailer.Mailer, passwordHasher utils.PasswordHasher, passwordPolicy *utils.PasswordPolicy, config UserConfig) UserService {
+	dummyPasswordHash, err := passwordHasher.Hash(uuid.New().String())
+	if err != nil {
+		panic(err)
+	}
+
+	return &UserServiceImpl{
+		userRepo:          userRepo,
+		userTokenRepo:     userTokenRepo,
+		auditService:      auditService,
+		mailer:            mailer,
+		passwordHasher:    passwordHasher,
+		passwordPolicy:    passwordPolicy,
+		config:            config,
+		dummyPasswordHash: dummyPasswordHash,
+	}
+}
+
+func (s *UserServiceImpl) hashPassword(password string) (string, error) {
+	if err := s.passwordPolicy.Validate(password); err != nil {
+		return "", errors.NewBadRequestError(err.Error())
+	}
+
+	hashedPassword, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		logger.Error(err)
+		return "", errors.NewInternalServerError()
+	}
+	return hashedPassword, nil
 }
 
-func NewUserService(userRepo domain.UserRepository) UserService {
-	return &UserServiceImpl{userRepo}
+// VerifyPassword checks a nil user against a dummy hash so unknown accounts
+// take as long as wrong passwords. Hashes made with outdated parameters or
+// bcrypt are replaced after a successful check.
+func (s *UserServiceImpl) VerifyPassword(user *domain.User, password string) bool {
+	hash := s.dummyPasswordHash
+	if user != nil {
+		hash = user.Password
+	}
+
+	ok, err := s.passwordHasher.Verify(hash, password)
+	if err != nil {
+		logger.Error(err)
+	}
+	if !ok || user == nil {
+		return false
+	}
+
+	if s.passwordHasher.NeedsRehash(user.Password) {
+		hashedPassword, err := s.passwordHasher.Hash(password)
+		if err != nil {
+			logger.Error(err)
+			return true
+		}
+		if err := s.userRepo.SetPassword(uuid.MustParse(user.ID), hashedPassword); err != nil {
+			logger.Error(err)
+			return true
+		}
+		user.Password = hashedPassword
+	}
+	return true
 }
 
 func (s *UserServiceImpl) GetUserByID(ID uuid.UUID) (*domain.User, error) {
@@ -51,30 +144,65 @@ func (s *UserServiceImpl) GetUserByID(ID uuid.UUID) (*domain.User, error) {
 }
 
 func (s *UserServiceImpl) CreateUser(createUserDto *dto.CreateUserDto) (*domain.User, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(createUserDto.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.hashPassword(createUserDto.Password)
 	if err != nil {
-		logger.Error(err)
-		return nil, errors.NewInternalServerError()
+		return nil, err
 	}
 	user := &domain.User{
 		Username: createUserDto.Username,
 		Email:    createUserDto.Email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		ShortBio: createUserDto.ShortBio,
+		Role:     domain.RoleUser,
 	}
 	result, err := s.userRepo.Create(user)
 	if err != nil {
 		logger.Error(err)
 		if err == gorm.ErrDuplicatedKey {
-			return nil, errors.NewBadRequestError("Duplicate username or email")
+			return nil, errors.NewConflictError("Duplicate username or email")
 		}
 		return nil, errors.NewBadRequestError(err.Error())
 	}
+
+	if err := s.SendEmailVerification(result); err != nil {
+		logger.Error(err)
+	}
 	return result, nil
 }
 
-func (s *UserServiceImpl) GetAllUsers() ([]domain.User, error) {
-	users, err := s.userRepo.FindAll()
+// CreateExternalUser creates a user whose email was verified by an identity
+// provider. The random password can only be replaced through a password reset.
+func (s *UserServiceImpl) CreateExternalUser(username, email string) (*domain.User, error) {
+	password, err := utils.GenerateRandomToken()
+	if err != nil {
+		logger.Error(err)
+		return nil, errors.NewInternalServerError()
+	}
+	hashedPassword, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		logger.Error(err)
+		return nil, errors.NewInternalServerError()
+	}
+	user := &domain.User{
+		Username:      username,
+		Email:         email,
+		EmailVerified: true,
+		Password:      hashedPassword,
+		Role:          domain.RoleUser,
+	}
+	result, err := s.userRepo.Create(user)
+	if err != nil {
+		logger.Error(err)
+		if err == gorm.ErrDuplicatedKey {
+			return nil, errors.NewConflictError("Duplicate username or email")
+		}
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	return result, nil
+}
+
+func (s *UserServiceImpl) GetAllUsers(page domain.PageRequest) (*domain.Page[domain.User], error) {
+	users, err := s.userRepo.FindAll(page)
 	if err != nil {
 		logger.Error(err)
 		return nil, err
@@ -106,72 +234,384 @@ func (s *UserServiceImpl) GetUserByEmail(email string) (*domain.User, error) {
 	return user, nil
 }
 
-func (s *UserServiceImpl) UpdateUser(ID uuid.UUID, updateUserDto *dto.UpdateUserDto) (*domain.User, error) {
-	fmt.Println("updateUserDto: ", updateUserDto)
+// UpdateUser needs the current password to change the email or password. A
+// password change signs out every session but sessionID, the one making the
+// change.
+func (s *UserServiceImpl) UpdateUser(actorID, ID, sessionID uuid.UUID, updateUserDto *dto.UpdateUserDto, clientInfo dto.ClientInfoDto) (*domain.User, error) {
+	if actorID != ID {
+		logger.Error("You can't update another user's account")
+		return nil, errors.NewForbiddenError("You can't update another user's account")
+	}
+
+	existingUser, err := s.GetUserByID(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	emailChanged := updateUserDto.Email != "" && updateUserDto.Email != existingUser.Email
+	if (emailChanged || updateUserDto.Password != "") && !s.VerifyPassword(existingUser, updateUserDto.CurrentPassword) {
+		logger.Error("invalid current password")
+		return nil, errors.NewUnauthorizedError("invalid current password")
+	}
+
 	user := &domain.User{
 		Username: updateUserDto.Username,
+		Email:    updateUserDto.Email,
 		ShortBio: updateUserDto.ShortBio,
 	}
 	if updateUserDto.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(updateUserDto.Password), bcrypt.DefaultCost)
+		hashedPassword, err := s.hashPassword(updateUserDto.Password)
 		if err != nil {
-			logger.Error(err)
-			return nil, errors.NewBadRequestError("Failed to hash password")
+			return nil, err
 		}
-		user.Password = string(hashedPassword)
+		user.Password = hashedPassword
 	}
 
 	result, err := s.userRepo.Update(ID, user)
 	if err != nil {
 		logger.Error(err)
+		if err == gorm.ErrDuplicatedKey {
+			return nil, errors.NewConflictError("Duplicate username or email")
+		}
 		return nil, err
 	}
+
+	if updateUserDto.Password != "" {
+		s.auditService.Record(newAuditEvent(domain.AuditEventPasswordChanged, domain.AuditOutcomeSuccess, existingUser.ID, clientInfo))
+
+		if err := s.DeleteUserSessions(ID, &sessionID); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+	}
+
+	if emailChanged {
+		event := newAuditEvent(domain.AuditEventEmailChanged, domain.AuditOutcomeSuccess, existingUser.ID, clientInfo)
+		event.Details = existingUser.Email + " -> " + updateUserDto.Email
+		s.auditService.Record(event)
+
+		if err := s.SendEmailVerification(result); err != nil {
+			logger.Error(err)
+		}
+	}
 	return result, nil
 }
 
-func (s *UserServiceImpl) DeleteUser(ID uuid.UUID) error {
-	err := s.userRepo.Delete(ID)
+// ResetPassword checks the new password before the reset token is used up, so
+// a rejected password leaves the token valid for another try.
+func (s *UserServiceImpl) ResetPassword(tokenID, ID uuid.UUID, password string, clientInfo dto.ClientInfoDto) error {
+	hashedPassword, err := s.hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	used, err := s.userRepo.ResetPassword(tokenID, ID, hashedPassword)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if !used {
+		return errors.NewBadRequestError("invalid or expired reset token")
+	}
+
+	s.auditService.Record(newAuditEvent(domain.AuditEventPasswordChanged, domain.AuditOutcomeSuccess, ID.String(), clientInfo))
+	return nil
+}
+
+// DeleteUser schedules the deletion of the actor's own account and deletes
+// other accounts immediately, which needs PermissionDeleteAnyUser.
+func (s *UserServiceImpl) DeleteUser(actorID, ID uuid.UUID, clientInfo dto.ClientInfoDto) error {
+	if actorID == ID {
+		_, err := s.RequestDeletion(ID, clientInfo)
+		return err
+	}
+
+	event := newAuditEvent(domain.AuditEventAccountDeleted, domain.AuditOutcomeSuccess, ID.String(), clientInfo)
+	actorIDString := actorID.String()
+	event.ActorID = &actorIDString
+
+	actor, err := s.GetUserByID(actorID)
+	if err != nil {
+		return err
+	}
+
+	if !actor.Role.HasPermission(domain.PermissionDeleteAnyUser) {
+		logger.Error("You can't delete another user's account")
+		event.Outcome = domain.AuditOutcomeFailure
+		event.Details = "permission denied"
+		s.auditService.Record(event)
+		return errors.NewForbiddenError("You can't delete another user's account")
+	}
+
+	_, err = s.GetUserByID(ID)
+	if err != nil {
+		return err
+	}
+
+	err = s.userRepo.Delete(ID)
 	if err != nil {
 		logger.Error(err)
 		return errors.NewBadRequestError(err.Error())
 	}
+
+	s.auditService.Record(event)
 	return nil
 }
 
-func (s *UserServiceImpl) CreateUserAndSession(createUserDto *dto.CreateUserDto, refreshToken *string) (*domain.User, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(createUserDto.Password), bcrypt.DefaultCost)
+// RequestDeletion deactivates the account and signs it out everywhere. Its
+// content stays hidden until PurgeDeletedUsers removes it after the grace
+// period, unless the user logs in again first.
+func (s *UserServiceImpl) RequestDeletion(ID uuid.UUID, clientInfo dto.ClientInfoDto) (*time.Time, error) {
+	user, err := s.GetUserByID(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeletionDueAt != nil {
+		return user.DeletionDueAt, nil
+	}
+
+	dueAt := time.Now().Add(s.config.DeletionGracePeriod)
+	err = s.userRepo.SetDeletionDueAt(ID, &dueAt)
 	if err != nil {
 		logger.Error(err)
-		return nil, errors.NewInternalServerError()
+		return nil, err
+	}
+
+	err = s.DeleteUserSessions(ID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	event := newAuditEvent(domain.AuditEventDeletionRequested, domain.AuditOutcomeSuccess, user.ID, clientInfo)
+	event.Details = "due " + dueAt.UTC().Format(time.RFC3339)
+	s.auditService.Record(event)
+	return &dueAt, nil
+}
+
+func (s *UserServiceImpl) CancelDeletion(user *domain.User, clientInfo dto.ClientInfoDto) error {
+	if user.DeletionDueAt == nil {
+		return nil
+	}
+
+	err := s.userRepo.SetDeletionDueAt(uuid.MustParse(user.ID), nil)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	user.DeletionDueAt = nil
+
+	s.auditService.Record(newAuditEvent(domain.AuditEventDeletionCancelled, domain.AuditOutcomeSuccess, user.ID, clientInfo))
+	return nil
+}
+
+func (s *UserServiceImpl) PurgeDeletedUsers() error {
+	now := time.Now()
+	users, err := s.userRepo.FindDueForDeletion(now)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	for _, user := range users {
+		deleted, err := s.userRepo.DeleteIfDue(uuid.MustParse(user.ID), now)
+		if err != nil {
+			logger.Error(err)
+			continue
+		}
+		if !deleted {
+			continue
+		}
+
+		event := newAuditEvent(domain.AuditEventAccountDeleted, domain.AuditOutcomeSuccess, user.ID, dto.ClientInfoDto{})
+		event.ActorID = nil
+		event.Details = "grace period expired"
+		s.auditService.Record(event)
+	}
+	return nil
+}
+
+func (s *UserServiceImpl) UpdateUserRole(actorID, ID uuid.UUID, role domain.Role, clientInfo dto.ClientInfoDto) (*domain.User, error) {
+	if !role.Valid() {
+		return nil, errors.NewBadRequestError("invalid role")
+	}
+
+	actor, err := s.GetUserByID(actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := newAuditEvent(domain.AuditEventRoleChanged, domain.AuditOutcomeSuccess, ID.String(), clientInfo)
+	event.ActorID = &actor.ID
+
+	if !actor.Role.HasPermission(domain.PermissionManageRoles) {
+		logger.Error("You can't change user roles")
+		event.Outcome = domain.AuditOutcomeFailure
+		event.Details = "permission denied"
+		s.auditService.Record(event)
+		return nil, errors.NewForbiddenError("You can't change user roles")
+	}
+
+	if actorID == ID {
+		logger.Error("You can't change your own role")
+		return nil, errors.NewForbiddenError("You can't change your own role")
+	}
+
+	user, err := s.GetUserByID(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.userRepo.SetRole(ID, role)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	event.Details = string(user.Role) + " -> " + string(role)
+	s.auditService.Record(event)
+
+	user.Role = role
+	return user, nil
+}
+
+func (s *UserServiceImpl) CreateUserAndSession(createUserDto *dto.CreateUserDto, session *domain.UserSession) (*domain.User, error) {
+	hashedPassword, err := s.hashPassword(createUserDto.Password)
+	if err != nil {
+		return nil, err
 	}
 	user := &domain.User{
 		Username: createUserDto.Username,
 		Email:    createUserDto.Email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		ShortBio: createUserDto.ShortBio,
+		Role:     domain.RoleUser,
 	}
-	result, err := s.userRepo.CreateUserAndSession(user, refreshToken)
+	result, err := s.userRepo.CreateUserAndSession(user, session)
 	if err != nil {
 		logger.Error(err)
 		if err == gorm.ErrDuplicatedKey {
-			return nil, errors.NewBadRequestError("Duplicate username or email")
+			return nil, errors.NewConflictError("Duplicate username or email")
 		}
 		return nil, errors.NewBadRequestError(err.Error())
 	}
+
+	if err := s.SendEmailVerification(result); err != nil {
+		logger.Error(err)
+	}
 	return result, nil
 }
 
-func (s *UserServiceImpl) UpdateUserSession(userID uuid.UUID, refreshToken *string) error {
-	err := s.userRepo.UpdateSession(userID, refreshToken)
+func (s *UserServiceImpl) UpdateTwoFactor(ID uuid.UUID, secret *string, enabled bool) error {
+	err := s.userRepo.UpdateTwoFactor(ID, secret, enabled)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (s *UserServiceImpl) SetTwoFactorLastStep(ID uuid.UUID, step int64) error {
+	err := s.userRepo.SetTwoFactorLastStep(ID, step)
+	if err != nil {
+		logger.Error(err)
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewUnauthorizedError("Two-factor code already used")
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *UserServiceImpl) SendEmailVerification(user *domain.User) error {
+	if user.EmailVerified {
+		return errors.NewBadRequestError("Email already verified")
+	}
+
+	userID := uuid.MustParse(user.ID)
+	err := s.userTokenRepo.DeleteByUserID(userID, domain.UserTokenEmailVerification)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	token, err := utils.GenerateRandomToken()
+	if err != nil {
+		logger.Error(err)
+		return errors.NewInternalServerError()
+	}
+
+	userToken := &domain.UserToken{
+		UserID:    user.ID,
+		Purpose:   domain.UserTokenEmailVerification,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.config.EmailVerificationTTL),
+	}
+	err = s.userTokenRepo.Create(userToken)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	message := mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Use the link below to verify your email address. It expires in %v.\n\n%s/verify-email?token=%s\n",
+			s.config.EmailVerificationTTL, s.config.AppBaseURL, token),
+	}
+	err = s.mailer.Send(message)
+	if err != nil {
+		logger.Error(err)
+		return errors.NewInternalServerError()
+	}
+
+	return nil
+}
+
+func (s *UserServiceImpl) VerifyEmail(token string, clientInfo dto.ClientInfoDto) error {
+	userToken, err := s.userTokenRepo.FindByHash(domain.UserTokenEmailVerification, utils.HashToken(token))
+	if err != nil {
+		logger.Error(err)
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewBadRequestError("invalid or expired verification token")
+		}
+		return err
+	}
+
+	if userToken.UsedAt != nil || time.Now().After(userToken.ExpiresAt) {
+		return errors.NewBadRequestError("invalid or expired verification token")
+	}
+
+	used, err := s.userTokenRepo.MarkUsed(uuid.MustParse(userToken.ID))
 	if err != nil {
 		logger.Error(err)
 		return err
 	}
+	if !used {
+		return errors.NewBadRequestError("invalid or expired verification token")
+	}
+
+	err = s.userRepo.SetEmailVerified(uuid.MustParse(userToken.UserID), true)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	s.auditService.Record(newAuditEvent(domain.AuditEventEmailVerified, domain.AuditOutcomeSuccess, userToken.UserID, clientInfo))
 	return nil
 }
 
-func (s *UserServiceImpl) GetUserSession(userID uuid.UUID) (*domain.UserSession, error) {
-	session, err := s.userRepo.FindSession(userID)
+func (s *UserServiceImpl) CreateUserSession(session *domain.UserSession) (*domain.UserSession, error) {
+	result, err := s.userRepo.CreateSession(session)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *UserServiceImpl) GetUserSession(ID uuid.UUID) (*domain.UserSession, error) {
+	session, err := s.userRepo.FindSessionByID(ID)
 	if err != nil {
 		logger.Error(err)
 		if err == gorm.ErrRecordNotFound {
@@ -182,6 +622,42 @@ func (s *UserServiceImpl) GetUserSession(userID uuid.UUID) (*domain.UserSession,
 	return session, nil
 }
 
+func (s *UserServiceImpl) GetUserSessions(userID uuid.UUID) ([]domain.UserSession, error) {
+	sessions, err := s.userRepo.FindSessionsByUserID(userID)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (s *UserServiceImpl) UpdateUserSession(ID uuid.UUID, oldRefreshTokenID, refreshTokenID string) error {
+	err := s.userRepo.UpdateSession(ID, oldRefreshTokenID, refreshTokenID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (s *UserServiceImpl) DeleteUserSession(ID uuid.UUID) error {
+	err := s.userRepo.DeleteSession(ID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (s *UserServiceImpl) DeleteUserSessions(userID uuid.UUID, exceptID *uuid.UUID) error {
+	err := s.userRepo.DeleteSessionsByUserID(userID, exceptID)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
 func (s *UserServiceImpl) GetUserWithRelation(ID uuid.UUID) (*domain.User, error) {
 	user, err := s.userRepo.FindUserWithRelation(ID)
 	if err != nil {
@@ -203,8 +679,8 @@ func (s *UserServiceImpl) GetUsersWithRelation() ([]domain.User, error) {
 	return users, nil
 }
 
-func (s *UserServiceImpl) GetUserBookmarks(userID uuid.UUID) ([]domain.Bookmark, error) {
-	bookmarks, err := s.userRepo.FindUserBookmarks(userID)
+func (s *UserServiceImpl) GetUserBookmarks(userID uuid.UUID, page domain.PageRequest) (*domain.Page[domain.Bookmark], error) {
+	bookmarks, err := s.userRepo.FindUserBookmarks(userID, page)
 	if err != nil {
 		logger.Error(err)
 		if err == gorm.ErrRecordNotFound {
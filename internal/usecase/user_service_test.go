@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/ppondeu/go-post-api/internal/domain"
+	"github.com/ppondeu/go-post-api/internal/dto"
+	"github.com/ppondeu/go-post-api/internal/mailer"
+)
+
+func TestUpdateUserNeedsCurrentPasswordForCredentials(t *testing.T) {
+	users := testUserService(newFakeUserRepo(newFakeUserTokenRepo()), mailer.NewMemoryMailer())
+	user, err := users.CreateUser(&dto.CreateUserDto{Username: "alice", Email: "alice@example.com", Password: "old password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID := uuid.MustParse(user.ID)
+
+	for name, update := range map[string]dto.UpdateUserDto{
+		"email":          {Email: "mallory@example.com"},
+		"password":       {Password: "new password"},
+		"wrong password": {Password: "new password", CurrentPassword: "wrong password"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := users.UpdateUser(userID, userID, uuid.Nil, &update, dto.ClientInfoDto{})
+			assertAppError(t, err, http.StatusUnauthorized)
+		})
+	}
+
+	if _, err := users.UpdateUser(userID, userID, uuid.Nil, &dto.UpdateUserDto{ShortBio: "hello"}, dto.ClientInfoDto{}); err != nil {
+		t.Fatalf("profile update without the current password: %v", err)
+	}
+}
+
+func TestPasswordChangeRevokesOtherSessions(t *testing.T) {
+	userRepo := newFakeUserRepo(newFakeUserTokenRepo())
+	users := testUserService(userRepo, mailer.NewMemoryMailer())
+	user, err := users.CreateUser(&dto.CreateUserDto{Username: "alice", Email: "alice@example.com", Password: "old password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID := uuid.MustParse(user.ID)
+
+	var sessions []*domain.UserSession
+	for range 3 {
+		session, err := users.CreateUserSession(&domain.UserSession{UserID: user.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sessions = append(sessions, session)
+	}
+	current := uuid.MustParse(sessions[0].ID)
+
+	update := dto.UpdateUserDto{Password: "new password", CurrentPassword: "old password"}
+	if _, err := users.UpdateUser(userID, userID, current, &update, dto.ClientInfoDto{}); err != nil {
+		t.Fatal(err)
+	}
+
+	remaining, err := users.GetUserSessions(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 1 || remaining[0].ID != current.String() {
+		t.Errorf("sessions after the password change = %v, want only %s", remaining, current)
+	}
+
+	updated, err := users.GetUserByID(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !users.VerifyPassword(updated, "new password") {
+		t.Error("new password is not accepted")
+	}
+}
+
+func TestEmailChangeUnverifiesEmail(t *testing.T) {
+	userRepo := newFakeUserRepo(newFakeUserTokenRepo())
+	users := testUserService(userRepo, mailer.NewMemoryMailer())
+	user, err := users.CreateUser(&dto.CreateUserDto{Username: "alice", Email: "alice@example.com", Password: "old password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID := uuid.MustParse(user.ID)
+	if err := userRepo.SetEmailVerified(userID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	update := dto.UpdateUserDto{Email: "alice@example.org", CurrentPassword: "old password"}
+	updated, err := users.UpdateUser(userID, userID, uuid.Nil, &update, dto.ClientInfoDto{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.EmailVerified {
+		t.Error("the new email is marked verified")
+	}
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeTag case-folds a tag and collapses its whitespace into the name
+// shown to users, and derives the slug used in URLs from it. Different
+// spellings of one tag, like "Go Lang" and "go-lang", share a slug.
+func NormalizeTag(tag string) (name, slug string) {
+	name = strings.Join(strings.Fields(strings.ToLower(tag)), " ")
+
+	var b strings.Builder
+	dash := false
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return name, b.String()
+}
@@ -1,16 +0,0 @@
-package utils
-
-import "golang.org/x/crypto/bcrypt"
-
-func CompareHashAndPassword(hash, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-}
-
-func GenerateFromPassword(password string) (*string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, err
-	}
-	hashedPasswordStr := string(hashedPassword)
-	return &hashedPasswordStr, nil
-}